@@ -1,25 +1,68 @@
 package html
 
-import "sort"
+import (
+	"html"
+	"sort"
+)
 
 // Attributes is a contaner for element attributes, implements BaseElement
 type Attributes struct {
 	// attrs is a map of key/value attributes
 	attrs map[string]string
+
+	// scoped is the scoped class of the element, its style is added to the Document when rendered
+	scoped *Class
+
+	// text are the attributes set with AddTextAttr, written with their value escaped
+	text map[string]bool
 }
 
 // AddAttr will all a key/value attribute to an element
+// The value is written as it is, so it must be HTML. A value of "true" is written as a bare attribute and "false" leaves it out.
 func (a *Attributes) AddAttr(key string, value string) {
 	if a.attrs == nil {
 		a.attrs = make(map[string]string)
 	}
 	a.attrs[key] = value
+	delete(a.text, key)
+	if key == "class" {
+		a.scoped = nil
+	}
+}
+
+// AddTextAttr adds a key/value attribute whose value is text, such as a value from a user or a database.
+// The value is HTML escaped when written, and "true" and "false" are written as values.
+func (a *Attributes) AddTextAttr(key string, value string) {
+	a.AddAttr(key, value)
+	if a.text == nil {
+		a.text = make(map[string]bool)
+	}
+	a.text[key] = true
 }
 
 func (a *Attributes) GetAttr(key string) string {
 	return a.attrs[key]
 }
 
+// attributes returns the attributes of an element, for the TagWriter
+func (a *Attributes) attributes() *Attributes {
+	return a
+}
+
+// clone returns a copy of the attributes
+func (a *Attributes) clone() *Attributes {
+	c := &Attributes{}
+	for k, v := range a.attrs {
+		if a.text[k] {
+			c.AddTextAttr(k, v)
+		} else {
+			c.AddAttr(k, v)
+		}
+	}
+	c.scoped = a.scoped
+	return c
+}
+
 // StyleAttr will all a style key/value attribute to an element
 func (a *Attributes) Style(key string, value string) {
 	if a.attrs == nil {
@@ -48,6 +91,10 @@ func (a *Attributes) GetAttrs() string {
 
 	for _, k := range keys {
 		v := a.attrs[k]
+		if a.text[k] {
+			ret += " " + k + `="` + html.EscapeString(v) + `"`
+			continue
+		}
 		switch v {
 		case "false":
 		case "true":
@@ -61,6 +108,16 @@ func (a *Attributes) GetAttrs() string {
 
 func (a *Attributes) AddClass(c *Class) {
 	a.AddAttr("class", c.Name)
+	if c.style != nil {
+		a.scoped = c
+	}
+}
+
+func (a *Attributes) scopedClasses() []*Class {
+	if a.scoped == nil {
+		return nil
+	}
+	return []*Class{a.scoped}
 }
 
 func (a *Attributes) AddClassName(className string) {
@@ -0,0 +1,44 @@
+package html
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestAttributeValues checks that AddAttr values of the existing elements are written as they are, with "true" and "false"
+// as a bare or absent attribute, and AddTextAttr values are escaped once and keep "true" and "false"
+func TestAttributeValues(t *testing.T) {
+	style := P(Text("x"))
+	style.Style("font-family", `'Open Sans'`)
+
+	text := TextInput("name", 10)
+	text.AddTextAttr("value", `<Tom & "Jerry">`)
+	copyright := TextInput("copy", 10)
+	copyright.AddTextAttr("value", "&copy;")
+	yes := Hidden("yes", "")
+	yes.AddTextAttr("value", "true")
+	replaced := Hidden("replaced", "")
+	replaced.AddTextAttr("value", "<a>")
+	replaced.AddAttr("value", "&lt;b&gt;")
+
+	for _, tc := range []struct {
+		e        Element
+		expected string
+	}{
+		{Submit("Save &amp; close"), `<input name="submit" type="submit" value="Save &amp; close">`},
+		{Hidden("copy", "&copy;"), `<input name="copy" type="hidden" value="&copy;">`},
+		{Hidden("on", true), `<input name="on" type="hidden" value>`},
+		{Hidden("off", false), `<input name="off" type="hidden">`},
+		{Checkbox("c", "1").SetChecked(false), `<input name="c" type="checkbox" value="1">`},
+		{Image("/img?w=1&amp;h=2"), `<img src="/img?w=1&amp;h=2">`},
+		{style, `<p style="font-family:'Open Sans'">x`},
+		{text, `<input name="name" size="10" type="text" value="&lt;Tom &amp; &#34;Jerry&#34;&gt;">`},
+		{copyright, `<input name="copy" size="10" type="text" value="&amp;copy;">`},
+		{yes, `<input name="yes" type="hidden" value="true">`},
+		{replaced, `<input name="replaced" type="hidden" value="&lt;b&gt;">`},
+	} {
+		if got := renderString(tc.e.Write); !strings.HasPrefix(got, tc.expected) {
+			t.Errorf("got      %s\nexpected %s", got, tc.expected)
+		}
+	}
+}
@@ -3,9 +3,18 @@ package html
 // Head defines the HTML body element
 type BodyElement struct {
 	Container
+
+	// scripts are written at the end of the body, see Document.AddScript
+	scripts []*ScriptElement
 }
 
 // Write writes the HTML body tag and body data
 func (body *BodyElement) Write(tw *TagWriter) {
 	tw.WriteTag(TagBody, body)
 }
+
+// WriteContent writes the body elements followed by the scripts of the document
+func (body *BodyElement) WriteContent(tw *TagWriter) {
+	body.Container.WriteContent(tw)
+	writeScripts(tw, body.scripts)
+}
@@ -0,0 +1,214 @@
+package html
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// bundleVersions is the number of versions given out by Link which a bundle keeps serving
+const bundleVersions = 16
+
+// CSSBundle gathers Styles, StyleGroups and CSS from any number of documents into one minified
+// stylesheet, which is served by the bundle as an http.Handler.
+// The link to the bundle carries a hash of its content, so browsers can cache it for as long as it is unchanged.
+// Rules are never removed, so a bundle is for the styles of a site rather than CSS built for each request.
+type CSSBundle struct {
+	mu sync.RWMutex
+
+	// path is where the bundle is served
+	path string
+
+	// chunks are the minified rules in the order they were added, seen dedupes them
+	chunks []string
+	seen   map[string]bool
+
+	content   []byte
+	hash      string
+	integrity string
+
+	// versions are the last versions given out by Link, oldest first, so pages linked to an earlier
+	// version still get the content which matches their integrity hash
+	versions []bundleVersion
+}
+
+// bundleVersion is a version of a bundle given out by Link, chunks are only appended so it is the first n
+type bundleVersion struct {
+	hash string
+	n    int
+}
+
+// NewCSSBundle creates an empty bundle which will be served at path, such as "/css/site.css"
+func NewCSSBundle(path string) *CSSBundle {
+	b := &CSSBundle{
+		path: path,
+		seen: make(map[string]bool),
+	}
+	b.update()
+	return b
+}
+
+// AddStyle adds styles to the bundle, styles without associations are not added
+func (b *CSSBundle) AddStyle(styles ...*Style) error {
+	for _, style := range styles {
+		if err := b.add(renderString(style.Write)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddStyleGroup adds a @media or @supports group to the bundle
+func (b *CSSBundle) AddStyleGroup(group *StyleGroup) error {
+	if err := group.Err(); err != nil {
+		return err
+	}
+	return b.add(group.String())
+}
+
+// AddCSS adds CSS to the bundle, returning any errors found parsing it
+func (b *CSSBundle) AddCSS(css ...*CSSData) error {
+	for _, c := range css {
+		if c.err != nil {
+			return c.err
+		}
+		if err := b.add(c.css); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddDocument adds the CSS and Styles of a document to the bundle, in the order they are rendered
+func (b *CSSBundle) AddDocument(doc *Document) error {
+	doc.addScopedStyles()
+	if err := b.AddCSS(doc.head.css.css...); err != nil {
+		return err
+	}
+	for _, r := range doc.head.styles.rules {
+		if err := b.add(renderString(r.Write)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add minifies each rule of css and appends those the bundle does not already have
+func (b *CSSBundle) add(css string) error {
+	sheet, err := ParseCSS(css)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	changed := false
+	for _, rule := range sheet.Rules {
+		chunk := (&Stylesheet{Rules: []*CSSRule{rule}}).Minify()
+		if len(chunk) == 0 || b.seen[chunk] {
+			continue
+		}
+		b.seen[chunk] = true
+		b.chunks = append(b.chunks, chunk)
+		changed = true
+	}
+	if changed {
+		b.update()
+	}
+	return nil
+}
+
+// update rebuilds the content and hashes, must be called with the lock held
+func (b *CSSBundle) update() {
+	b.content = []byte(strings.Join(b.chunks, ""))
+	sum := sha256.Sum256(b.content)
+	b.hash = hex.EncodeToString(sum[:8])
+	b.integrity = SRIHash(b.content)
+}
+
+// CSS returns the bundled stylesheet
+func (b *CSSBundle) CSS() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return string(b.content)
+}
+
+// Hash returns the short content hash which versions the bundle
+func (b *CSSBundle) Hash() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.hash
+}
+
+// Link returns a stylesheet link to the current version of the bundle, with its integrity hash
+// The version is still served after more CSS is added, until the bundle has linked 16 newer versions
+func (b *CSSBundle) Link() *LinkElement {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n := len(b.versions); n == 0 || b.versions[n-1].hash != b.hash {
+		b.versions = append(b.versions, bundleVersion{hash: b.hash, n: len(b.chunks)})
+		if len(b.versions) > bundleVersions {
+			b.versions = b.versions[1:]
+		}
+	}
+	return StylesheetLink(b.path + "?v=" + b.hash).Integrity(b.integrity)
+}
+
+// ServeHTTP serves the bundle. Requests for the current version or one given out by Link, ?v=hash, get that version
+// and are cached by the browser forever, unknown versions are not found. Requests without a version get the current
+// content and must revalidate with the ETag.
+func (b *CSSBundle) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query().Get("v")
+	b.mu.RLock()
+	content, hash, found := b.content, b.hash, len(v) == 0 || v == b.hash
+	for _, version := range b.versions {
+		if !found && version.hash == v {
+			content, hash, found = []byte(strings.Join(b.chunks[:version.n], "")), v, true
+		}
+	}
+	b.mu.RUnlock()
+
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	etag := `"` + hash + `"`
+	h := w.Header()
+	h.Set("Content-Type", "text/css; charset=utf-8")
+	h.Set("ETag", etag)
+	if len(v) > 0 {
+		h.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		h.Set("Cache-Control", "no-cache")
+	}
+
+	if match := r.Header.Get("If-None-Match"); match == etag || match == "*" {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(content)
+}
+
+// LinkBundle moves the CSS and Styles of the document into the bundle and links the document to it
+func (doc *Document) LinkBundle(b *CSSBundle) error {
+	if err := b.AddDocument(doc); err != nil {
+		return err
+	}
+	if doc.head.linked == nil {
+		doc.head.linked = make(map[string]bool)
+	}
+	for name := range doc.head.styles.styles {
+		doc.head.linked[name] = true
+	}
+	doc.head.css.css = nil
+	doc.head.replaceStyles(NewStyles())
+	doc.head.AddStylesheet(b.Link())
+	return nil
+}
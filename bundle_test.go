@@ -0,0 +1,95 @@
+package html
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCSSBundle(t *testing.T) {
+	bundle := NewCSSBundle("/site.css")
+
+	for i := 0; i < 2; i++ {
+		doc := NewDocument()
+		doc.AddCSS(CSS(`/* shared */ p  { color : red }`))
+		style := NewStyle("cell", StyleDef{Key: "padding", Value: "2px 4px"})
+		style.AddElement("td")
+		doc.AddStyle(style)
+		doc.Head().AddStylesheet(StylesheetLink("/print.css").Media(MediaPrint))
+		if err := doc.LinkBundle(bundle); err != nil {
+			t.Fatal(err)
+		}
+
+		html := renderString(doc.Write)
+		if strings.Contains(html, "<style") {
+			t.Errorf("styles were not moved to the bundle: %s", html)
+		}
+		link := `<link href="/site.css?v=` + bundle.Hash() + `" integrity="sha384-`
+		if !strings.Contains(html, link) || !strings.Contains(html, `media="print"`) {
+			t.Errorf("missing links: %s", html)
+		}
+	}
+
+	if expected := "p{color:red}td{padding:2px 4px}"; bundle.CSS() != expected {
+		t.Errorf("bundle %q, expected %q", bundle.CSS(), expected)
+	}
+
+	w := httptest.NewRecorder()
+	bundle.ServeHTTP(w, httptest.NewRequest("GET", "/site.css?v="+bundle.Hash(), nil))
+	if w.Body.String() != bundle.CSS() || !strings.Contains(w.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("response %q %v", w.Body.String(), w.Header())
+	}
+
+	r := httptest.NewRequest("GET", "/site.css", nil)
+	r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	bundle.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("status %d, expected %d", w.Code, http.StatusNotModified)
+	}
+}
+
+func TestCSSBundleVersions(t *testing.T) {
+	bundle := NewCSSBundle("/site.css")
+	if err := bundle.AddCSS(CSS("p { color: red }")); err != nil {
+		t.Fatal(err)
+	}
+	old := bundle.Link()
+	if err := bundle.AddCSS(CSS("td { padding: 2px }")); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	bundle.ServeHTTP(w, httptest.NewRequest("GET", old.GetAttr("href"), nil))
+	if w.Body.String() != "p{color:red}" || SRIHash(w.Body.Bytes()) != old.GetAttr("integrity") {
+		t.Errorf("old version %q does not match its integrity", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	bundle.ServeHTTP(w, httptest.NewRequest("GET", "/site.css?v=0123456789abcdef", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown version status %d, expected %d", w.Code, http.StatusNotFound)
+	}
+
+	// the current version is served before it is linked
+	w = httptest.NewRecorder()
+	bundle.ServeHTTP(w, httptest.NewRequest("GET", "/site.css?v="+bundle.Hash(), nil))
+	if w.Code != http.StatusOK || w.Body.String() != bundle.CSS() {
+		t.Errorf("current version status %d %q", w.Code, w.Body.String())
+	}
+
+	// only the last versions are kept
+	for i := 0; i < bundleVersions; i++ {
+		if err := bundle.AddCSS(CSS(fmt.Sprintf(".c%d { margin: 0 }", i))); err != nil {
+			t.Fatal(err)
+		}
+		bundle.Link()
+	}
+	w = httptest.NewRecorder()
+	bundle.ServeHTTP(w, httptest.NewRequest("GET", old.GetAttr("href"), nil))
+	if w.Code != http.StatusNotFound || len(bundle.versions) != bundleVersions {
+		t.Errorf("evicted version status %d, %d versions kept", w.Code, len(bundle.versions))
+	}
+}
@@ -0,0 +1,87 @@
+package html
+
+import "strconv"
+
+// Choice is an option of a RadioGroup or CheckboxGroup, Display is the text of its label and Value is submitted
+type Choice struct {
+	Display string
+	Value   string
+}
+
+// Choices makes a list of Choice from display, value pairs
+func Choices(pairs ...string) []Choice {
+	var choices []Choice
+	for i := 0; i+1 < len(pairs); i += 2 {
+		choices = append(choices, Choice{Display: pairs[i], Value: pairs[i+1]})
+	}
+	return choices
+}
+
+// ChoiceGroupElement is a group of labelled radio buttons or checkboxes sharing a name
+type ChoiceGroupElement struct {
+	Container
+
+	name   string
+	inputs []*InputElement
+}
+
+// RadioGroup creates a radio button for each choice, with the choice of value selected
+func RadioGroup(name string, choices []Choice, selected string) *ChoiceGroupElement {
+	return choiceGroup("radio", "radiogroup", name, choices).Select(selected)
+}
+
+// CheckboxGroup creates a checkbox for each choice, with the choices of values checked
+func CheckboxGroup(name string, choices []Choice, selected []string) *ChoiceGroupElement {
+	return choiceGroup("checkbox", "group", name, choices).Select(selected...)
+}
+
+func choiceGroup(typ string, role string, name string, choices []Choice) *ChoiceGroupElement {
+	g := &ChoiceGroupElement{name: name}
+	id := "html_choice_" + getUniqueId()
+	g.AddAttr("id", id)
+	g.AddAttr("role", role)
+	for n, c := range choices {
+		i := input(typ, name)
+		i.AddAttr("id", id+"-"+strconv.Itoa(n))
+		i.AddTextAttr("value", c.Value)
+		label := textLabel(c.Display)
+		label.AddAttr("for", i.GetAttr("id"))
+		g.inputs = append(g.inputs, i)
+		g.Add(i, label)
+	}
+	return g
+}
+
+// Select checks the inputs with the values, and unchecks the others
+func (g *ChoiceGroupElement) Select(values ...string) *ChoiceGroupElement {
+	for _, i := range g.inputs {
+		i.AddAttr("checked", strconv.FormatBool(containsString(values, i.GetAttr("value"))))
+	}
+	return g
+}
+
+// Selected returns the values of the checked inputs
+func (g *ChoiceGroupElement) Selected() []string {
+	var values []string
+	for _, i := range g.inputs {
+		if i.GetAttr("checked") == "true" {
+			values = append(values, i.GetAttr("value"))
+		}
+	}
+	return values
+}
+
+// Label names the group for screen readers
+func (g *ChoiceGroupElement) Label(label string) *ChoiceGroupElement {
+	g.AddTextAttr("aria-label", label)
+	return g
+}
+
+// Write writes the group as a div, with any error of the field after the group rather than its first input
+func (g *ChoiceGroupElement) Write(tw *TagWriter) {
+	held := tw.holdFieldError(g.name)
+	tw.WriteTag(TagDiv, g)
+	if held {
+		tw.writeNamedFieldError(g.name)
+	}
+}
@@ -0,0 +1,78 @@
+package html
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChoiceGroups(t *testing.T) {
+	f := Form(NewLink("/order")).SetName("order")
+	radio := RadioGroup("size", Choices("Small", "s", "Large & heavy", "l"), "l").Label("Size")
+	checks := CheckboxGroup("tags", Choices("Red", "r", "Blue", "b"), []string{"r", "b"})
+	f.Add(radio, checks)
+	f.AddValidator("tags", Required())
+
+	if got := checks.Selected(); !reflect.DeepEqual(got, []string{"r", "b"}) {
+		t.Errorf("selected %v", got)
+	}
+
+	id := radio.GetAttr("id")
+	html := renderString(f.Write)
+	for _, expected := range []string{
+		`<div aria-label="Size" id="` + id + `" role="radiogroup"><input id="` + id + `-0" name="size" type="radio" value="s">`,
+		`<label for="` + id + `-1">Large &amp; heavy</label>`,
+		`<input checked id="` + id + `-1" name="size" type="radio" value="l">`,
+		`role="group"><input checked id="` + checks.GetAttr("id") + `-0" name="tags" type="checkbox" value="r">`,
+		`querySelectorAll("input[name=\"tags\"]:checked")`,
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("missing %s in\n%s", expected, html)
+		}
+	}
+	if strings.Contains(html, "required id=") {
+		t.Errorf("required on checkboxes in\n%s", html)
+	}
+
+	f.SetValues(url.Values{"size": {"s"}}).SetErrors(map[string]string{"size": "Pick a size"})
+	if got := radio.Selected(); !reflect.DeepEqual(got, []string{"s"}) {
+		t.Errorf("selected %v after SetValues", got)
+	}
+	if got := checks.Selected(); len(got) != 0 {
+		t.Errorf("selected %v after SetValues", got)
+	}
+	html = renderString(f.Write)
+	if !strings.Contains(html, "</div>\n<span class=\"field-error\" id=\"order-size-error\"") || strings.Count(html, "Pick a size") != 1 {
+		t.Errorf("error not after the group in\n%s", html)
+	}
+
+	if got := PlainText(radio); got != "(x) Small ( ) Large & heavy\n" {
+		t.Errorf("plain text %q", got)
+	}
+}
+
+func TestChoiceGroupBoolValues(t *testing.T) {
+	f := Form(NewLink("/")).SetName("f")
+	g := RadioGroup("b", Choices("Yes", "true", "No", "false"), "false")
+	f.Add(g)
+
+	html := renderString(f.Write)
+	id := g.GetAttr("id")
+	for _, expected := range []string{
+		`<input id="` + id + `-0" name="b" type="radio" value="true">`,
+		`<input checked id="` + id + `-1" name="b" type="radio" value="false">`,
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("missing %s in\n%s", expected, html)
+		}
+	}
+	if got := submitForm(html)["b"]; !reflect.DeepEqual(got, []string{"false"}) {
+		t.Errorf("submitted %v", got)
+	}
+
+	f.SetValues(url.Values{"b": {"true"}})
+	if got := g.Selected(); !reflect.DeepEqual(got, []string{"true"}) {
+		t.Errorf("selected %v after SetValues", got)
+	}
+}
@@ -1,5 +1,497 @@
 package html
 
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
 const (
 	ColorRed = "red"
 )
+
+// WCAG 2 minimum contrast ratios between text and its background
+const (
+	// ContrastAA is the minimum for normal text
+	ContrastAA = 4.5
+
+	// ContrastAALarge is the minimum for large text, 18pt or 14pt bold
+	ContrastAALarge = 3.0
+
+	// ContrastAAA is the enhanced minimum for normal text
+	ContrastAAA = 7.0
+)
+
+var (
+	Black = Color{A: 1}
+	White = Color{R: 255, G: 255, B: 255, A: 1}
+)
+
+// Color is an sRGB color with an alpha channel, A is from 0 (transparent) to 1 (opaque)
+// The zero Color is transparent black
+type Color struct {
+	R, G, B uint8
+	A       float64
+}
+
+// RGB creates an opaque color from red, green and blue components
+func RGB(r, g, b uint8) Color {
+	return Color{R: r, G: g, B: b, A: 1}
+}
+
+// RGBA creates a color from red, green and blue components and an alpha from 0 to 1
+func RGBA(r, g, b uint8, a float64) Color {
+	return Color{R: r, G: g, B: b, A: clamp(a, 0, 1)}
+}
+
+// HSL creates an opaque color from a hue in degrees, and saturation and lightness from 0 to 1
+func HSL(h, s, l float64) Color {
+	return HSLA(h, s, l, 1)
+}
+
+// HSLA creates a color from a hue in degrees, and saturation, lightness and alpha from 0 to 1
+func HSLA(h, s, l, a float64) Color {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	s = clamp(s, 0, 1)
+	l = clamp(l, 0, 1)
+
+	// https://www.w3.org/TR/css-color-4/#hsl-to-rgb
+	f := func(n float64) uint8 {
+		k := math.Mod(n+h/30, 12)
+		return toByte(l - s*math.Min(l, 1-l)*math.Max(-1, math.Min(math.Min(k-3, 9-k), 1)))
+	}
+	return Color{R: f(0), G: f(8), B: f(4), A: clamp(a, 0, 1)}
+}
+
+// ParseColor parses a CSS color: a name such as "rebeccapurple" or "transparent", #rgb, #rgba, #rrggbb, #rrggbbaa,
+// rgb(), rgba(), hsl() or hsla(), with either comma or space separated arguments
+func ParseColor(s string) (Color, error) {
+	str := strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColors[str]; ok {
+		return c, nil
+	}
+	if strings.HasPrefix(str, "#") {
+		if c, ok := parseHexColor(str[1:]); ok {
+			return c, nil
+		}
+		return Color{}, fmt.Errorf("color %q: invalid hex color", s)
+	}
+
+	open := strings.IndexByte(str, '(')
+	if open < 0 || !strings.HasSuffix(str, ")") {
+		return Color{}, fmt.Errorf("color %q: unknown color", s)
+	}
+	fn := str[:open]
+	args := strings.FieldsFunc(str[open+1:len(str)-1], func(r rune) bool {
+		return r == ',' || r == '/' || r == ' ' || r == '\t'
+	})
+	if len(args) != 3 && len(args) != 4 {
+		return Color{}, fmt.Errorf("color %q: expected 3 or 4 values", s)
+	}
+
+	alpha := 1.0
+	if len(args) == 4 {
+		a, err := parseColorValue(args[3], 1)
+		if err != nil {
+			return Color{}, fmt.Errorf("color %q: %v", s, err)
+		}
+		alpha = a
+	}
+
+	switch fn {
+	case "rgb", "rgba":
+		var rgb [3]uint8
+		for i := range rgb {
+			v, err := parseColorValue(args[i], 255)
+			if err != nil {
+				return Color{}, fmt.Errorf("color %q: %v", s, err)
+			}
+			rgb[i] = toByte(v / 255)
+		}
+		return RGBA(rgb[0], rgb[1], rgb[2], alpha), nil
+	case "hsl", "hsla":
+		h, err := strconv.ParseFloat(strings.TrimSuffix(args[0], "deg"), 64)
+		if err != nil {
+			return Color{}, fmt.Errorf("color %q: invalid hue %q", s, args[0])
+		}
+		var sl [2]float64
+		for i := range sl {
+			if !strings.HasSuffix(args[i+1], "%") {
+				return Color{}, fmt.Errorf("color %q: expected percentage, got %q", s, args[i+1])
+			}
+			if sl[i], err = parseColorValue(args[i+1], 1); err != nil {
+				return Color{}, fmt.Errorf("color %q: %v", s, err)
+			}
+		}
+		return HSLA(h, sl[0], sl[1], alpha), nil
+	}
+	return Color{}, fmt.Errorf("color %q: unknown function %s()", s, fn)
+}
+
+// MustColor is like ParseColor but panics if s is not a valid color, for colors fixed in code
+func MustColor(s string) Color {
+	c, err := ParseColor(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// parseHexColor parses the digits of #rgb, #rgba, #rrggbb or #rrggbbaa
+func parseHexColor(s string) (Color, bool) {
+	switch len(s) {
+	case 3, 4:
+		s2 := make([]byte, 0, 8)
+		for i := 0; i < len(s); i++ {
+			s2 = append(s2, s[i], s[i])
+		}
+		s = string(s2)
+	case 6, 8:
+	default:
+		return Color{}, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return Color{}, false
+	}
+	if len(s) == 6 {
+		v = v<<8 | 0xff
+	}
+	return Color{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: float64(uint8(v)) / 255}, true
+}
+
+// parseColorValue parses a number or a percentage, percentages are scaled to max
+func parseColorValue(s string, max float64) (float64, error) {
+	scale := 1.0
+	if strings.HasSuffix(s, "%") {
+		s = s[:len(s)-1]
+		scale = max / 100
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	return clamp(v*scale, 0, max), nil
+}
+
+// String returns the color as CSS, #rrggbb when opaque and rgba() otherwise
+func (c Color) String() string {
+	if c.A >= 1 {
+		return c.Hex()
+	}
+	return fmt.Sprintf("rgba(%d, %d, %d, %s)", c.R, c.G, c.B, strconv.FormatFloat(math.Round(c.A*1000)/1000, 'f', -1, 64))
+}
+
+// Hex returns the color as #rrggbb, ignoring alpha
+func (c Color) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// HSL returns the hue in degrees, and saturation and lightness from 0 to 1
+func (c Color) HSL() (h, s, l float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	l = (max + min) / 2
+	d := max - min
+	if d == 0 {
+		return 0, 0, l
+	}
+	s = d / (1 - math.Abs(2*l-1))
+	switch max {
+	case r:
+		h = math.Mod((g-b)/d, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, s, l
+}
+
+// Lighten increases the lightness by amount, from 0 to 1
+func (c Color) Lighten(amount float64) Color {
+	h, s, l := c.HSL()
+	return HSLA(h, s, l+amount, c.A)
+}
+
+// Darken decreases the lightness by amount, from 0 to 1
+func (c Color) Darken(amount float64) Color {
+	return c.Lighten(-amount)
+}
+
+// Mix mixes the color with other, weight is the amount of other from 0 to 1
+func (c Color) Mix(other Color, weight float64) Color {
+	w := clamp(weight, 0, 1)
+	mix := func(a, b uint8) uint8 {
+		return toByte((float64(a)*(1-w) + float64(b)*w) / 255)
+	}
+	return Color{
+		R: mix(c.R, other.R),
+		G: mix(c.G, other.G),
+		B: mix(c.B, other.B),
+		A: c.A*(1-w) + other.A*w,
+	}
+}
+
+// Alpha returns the color with its alpha set to a, from 0 to 1
+func (c Color) Alpha(a float64) Color {
+	c.A = clamp(a, 0, 1)
+	return c
+}
+
+// Luminance returns the WCAG relative luminance, from 0 for black to 1 for white
+func (c Color) Luminance() float64 {
+	channel := func(v uint8) float64 {
+		f := float64(v) / 255
+		if f <= 0.04045 {
+			return f / 12.92
+		}
+		return math.Pow((f+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(c.R) + 0.7152*channel(c.G) + 0.0722*channel(c.B)
+}
+
+// Contrast returns the WCAG contrast ratio of the color as text on background bg, from 1 to 21
+// A translucent color is blended over bg, a translucent bg is blended over white
+func (c Color) Contrast(bg Color) float64 {
+	if bg.A < 1 {
+		bg = White.Mix(bg.Alpha(1), bg.A)
+	}
+	if c.A < 1 {
+		c = bg.Mix(c.Alpha(1), c.A)
+	}
+	l1, l2 := c.Luminance(), bg.Luminance()
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// Readable returns whichever of black or white has the higher contrast as text on the color
+func (c Color) Readable() Color {
+	if Black.Contrast(c) >= White.Contrast(c) {
+		return Black
+	}
+	return White
+}
+
+// ReadableFrom returns the first of candidates which has at least ratio contrast on the color,
+// or black or white if none do
+func (c Color) ReadableFrom(ratio float64, candidates ...Color) Color {
+	for _, fg := range candidates {
+		if fg.Contrast(c) >= ratio {
+			return fg
+		}
+	}
+	return c.Readable()
+}
+
+// Shades returns n colors of the same hue and saturation, from light to dark, nil if n is not positive
+func (c Color) Shades(n int) []Color {
+	if n <= 0 {
+		return nil
+	}
+	h, s, _ := c.HSL()
+	colors := make([]Color, n)
+	for i := range colors {
+		colors[i] = HSLA(h, s, 0.95-0.85*float64(i)/math.Max(1, float64(n-1)), c.A)
+	}
+	return colors
+}
+
+// Scale returns n colors blending evenly from the color to other, including both, nil if n is not positive
+func (c Color) Scale(other Color, n int) []Color {
+	if n <= 0 {
+		return nil
+	}
+	colors := make([]Color, n)
+	for i := range colors {
+		colors[i] = c.Mix(other, float64(i)/math.Max(1, float64(n-1)))
+	}
+	return colors
+}
+
+// Palette returns n colors with the saturation and lightness of the color and hues evenly spaced around the
+// color wheel, starting with the color, for series in charts and legends, nil if n is not positive
+func (c Color) Palette(n int) []Color {
+	if n <= 0 {
+		return nil
+	}
+	h, s, l := c.HSL()
+	colors := make([]Color, n)
+	for i := range colors {
+		colors[i] = HSLA(h+360*float64(i)/float64(n), s, l, c.A)
+	}
+	return colors
+}
+
+func clamp(v, min, max float64) float64 {
+	return math.Max(min, math.Min(max, v))
+}
+
+// toByte converts a channel from 0 to 1 to 0 to 255
+func toByte(v float64) uint8 {
+	return uint8(math.Round(clamp(v, 0, 1) * 255))
+}
+
+// namedColors are the CSS named colors, https://www.w3.org/TR/css-color-4/#named-colors
+var namedColors = map[string]Color{
+	"transparent":          {},
+	"aliceblue":            RGB(240, 248, 255),
+	"antiquewhite":         RGB(250, 235, 215),
+	"aqua":                 RGB(0, 255, 255),
+	"aquamarine":           RGB(127, 255, 212),
+	"azure":                RGB(240, 255, 255),
+	"beige":                RGB(245, 245, 220),
+	"bisque":               RGB(255, 228, 196),
+	"black":                RGB(0, 0, 0),
+	"blanchedalmond":       RGB(255, 235, 205),
+	"blue":                 RGB(0, 0, 255),
+	"blueviolet":           RGB(138, 43, 226),
+	"brown":                RGB(165, 42, 42),
+	"burlywood":            RGB(222, 184, 135),
+	"cadetblue":            RGB(95, 158, 160),
+	"chartreuse":           RGB(127, 255, 0),
+	"chocolate":            RGB(210, 105, 30),
+	"coral":                RGB(255, 127, 80),
+	"cornflowerblue":       RGB(100, 149, 237),
+	"cornsilk":             RGB(255, 248, 220),
+	"crimson":              RGB(220, 20, 60),
+	"cyan":                 RGB(0, 255, 255),
+	"darkblue":             RGB(0, 0, 139),
+	"darkcyan":             RGB(0, 139, 139),
+	"darkgoldenrod":        RGB(184, 134, 11),
+	"darkgray":             RGB(169, 169, 169),
+	"darkgreen":            RGB(0, 100, 0),
+	"darkgrey":             RGB(169, 169, 169),
+	"darkkhaki":            RGB(189, 183, 107),
+	"darkmagenta":          RGB(139, 0, 139),
+	"darkolivegreen":       RGB(85, 107, 47),
+	"darkorange":           RGB(255, 140, 0),
+	"darkorchid":           RGB(153, 50, 204),
+	"darkred":              RGB(139, 0, 0),
+	"darksalmon":           RGB(233, 150, 122),
+	"darkseagreen":         RGB(143, 188, 143),
+	"darkslateblue":        RGB(72, 61, 139),
+	"darkslategray":        RGB(47, 79, 79),
+	"darkslategrey":        RGB(47, 79, 79),
+	"darkturquoise":        RGB(0, 206, 209),
+	"darkviolet":           RGB(148, 0, 211),
+	"deeppink":             RGB(255, 20, 147),
+	"deepskyblue":          RGB(0, 191, 255),
+	"dimgray":              RGB(105, 105, 105),
+	"dimgrey":              RGB(105, 105, 105),
+	"dodgerblue":           RGB(30, 144, 255),
+	"firebrick":            RGB(178, 34, 34),
+	"floralwhite":          RGB(255, 250, 240),
+	"forestgreen":          RGB(34, 139, 34),
+	"fuchsia":              RGB(255, 0, 255),
+	"gainsboro":            RGB(220, 220, 220),
+	"ghostwhite":           RGB(248, 248, 255),
+	"gold":                 RGB(255, 215, 0),
+	"goldenrod":            RGB(218, 165, 32),
+	"gray":                 RGB(128, 128, 128),
+	"green":                RGB(0, 128, 0),
+	"greenyellow":          RGB(173, 255, 47),
+	"grey":                 RGB(128, 128, 128),
+	"honeydew":             RGB(240, 255, 240),
+	"hotpink":              RGB(255, 105, 180),
+	"indianred":            RGB(205, 92, 92),
+	"indigo":               RGB(75, 0, 130),
+	"ivory":                RGB(255, 255, 240),
+	"khaki":                RGB(240, 230, 140),
+	"lavender":             RGB(230, 230, 250),
+	"lavenderblush":        RGB(255, 240, 245),
+	"lawngreen":            RGB(124, 252, 0),
+	"lemonchiffon":         RGB(255, 250, 205),
+	"lightblue":            RGB(173, 216, 230),
+	"lightcoral":           RGB(240, 128, 128),
+	"lightcyan":            RGB(224, 255, 255),
+	"lightgoldenrodyellow": RGB(250, 250, 210),
+	"lightgray":            RGB(211, 211, 211),
+	"lightgreen":           RGB(144, 238, 144),
+	"lightgrey":            RGB(211, 211, 211),
+	"lightpink":            RGB(255, 182, 193),
+	"lightsalmon":          RGB(255, 160, 122),
+	"lightseagreen":        RGB(32, 178, 170),
+	"lightskyblue":         RGB(135, 206, 250),
+	"lightslategray":       RGB(119, 136, 153),
+	"lightslategrey":       RGB(119, 136, 153),
+	"lightsteelblue":       RGB(176, 196, 222),
+	"lightyellow":          RGB(255, 255, 224),
+	"lime":                 RGB(0, 255, 0),
+	"limegreen":            RGB(50, 205, 50),
+	"linen":                RGB(250, 240, 230),
+	"magenta":              RGB(255, 0, 255),
+	"maroon":               RGB(128, 0, 0),
+	"mediumaquamarine":     RGB(102, 205, 170),
+	"mediumblue":           RGB(0, 0, 205),
+	"mediumorchid":         RGB(186, 85, 211),
+	"mediumpurple":         RGB(147, 112, 219),
+	"mediumseagreen":       RGB(60, 179, 113),
+	"mediumslateblue":      RGB(123, 104, 238),
+	"mediumspringgreen":    RGB(0, 250, 154),
+	"mediumturquoise":      RGB(72, 209, 204),
+	"mediumvioletred":      RGB(199, 21, 133),
+	"midnightblue":         RGB(25, 25, 112),
+	"mintcream":            RGB(245, 255, 250),
+	"mistyrose":            RGB(255, 228, 225),
+	"moccasin":             RGB(255, 228, 181),
+	"navajowhite":          RGB(255, 222, 173),
+	"navy":                 RGB(0, 0, 128),
+	"oldlace":              RGB(253, 245, 230),
+	"olive":                RGB(128, 128, 0),
+	"olivedrab":            RGB(107, 142, 35),
+	"orange":               RGB(255, 165, 0),
+	"orangered":            RGB(255, 69, 0),
+	"orchid":               RGB(218, 112, 214),
+	"palegoldenrod":        RGB(238, 232, 170),
+	"palegreen":            RGB(152, 251, 152),
+	"paleturquoise":        RGB(175, 238, 238),
+	"palevioletred":        RGB(219, 112, 147),
+	"papayawhip":           RGB(255, 239, 213),
+	"peachpuff":            RGB(255, 218, 185),
+	"peru":                 RGB(205, 133, 63),
+	"pink":                 RGB(255, 192, 203),
+	"plum":                 RGB(221, 160, 221),
+	"powderblue":           RGB(176, 224, 230),
+	"purple":               RGB(128, 0, 128),
+	"rebeccapurple":        RGB(102, 51, 153),
+	"red":                  RGB(255, 0, 0),
+	"rosybrown":            RGB(188, 143, 143),
+	"royalblue":            RGB(65, 105, 225),
+	"saddlebrown":          RGB(139, 69, 19),
+	"salmon":               RGB(250, 128, 114),
+	"sandybrown":           RGB(244, 164, 96),
+	"seagreen":             RGB(46, 139, 87),
+	"seashell":             RGB(255, 245, 238),
+	"sienna":               RGB(160, 82, 45),
+	"silver":               RGB(192, 192, 192),
+	"skyblue":              RGB(135, 206, 235),
+	"slateblue":            RGB(106, 90, 205),
+	"slategray":            RGB(112, 128, 144),
+	"slategrey":            RGB(112, 128, 144),
+	"snow":                 RGB(255, 250, 250),
+	"springgreen":          RGB(0, 255, 127),
+	"steelblue":            RGB(70, 130, 180),
+	"tan":                  RGB(210, 180, 140),
+	"teal":                 RGB(0, 128, 128),
+	"thistle":              RGB(216, 191, 216),
+	"tomato":               RGB(255, 99, 71),
+	"turquoise":            RGB(64, 224, 208),
+	"violet":               RGB(238, 130, 238),
+	"wheat":                RGB(245, 222, 179),
+	"white":                RGB(255, 255, 255),
+	"whitesmoke":           RGB(245, 245, 245),
+	"yellow":               RGB(255, 255, 0),
+	"yellowgreen":          RGB(154, 205, 50),
+}
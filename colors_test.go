@@ -0,0 +1,89 @@
+package html
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	for _, tc := range []struct {
+		s        string
+		expected Color
+	}{
+		{"red", RGB(255, 0, 0)},
+		{"RebeccaPurple", RGB(102, 51, 153)},
+		{"transparent", Color{}},
+		{"#0f8", RGB(0, 255, 136)},
+		{"#0f88", RGBA(0, 255, 136, 136.0/255)},
+		{"#336699", RGB(51, 102, 153)},
+		{"#33669980", RGBA(51, 102, 153, 128.0/255)},
+		{"rgb(51, 102, 153)", RGB(51, 102, 153)},
+		{"rgba(100%, 0%, 0%, 0.5)", RGBA(255, 0, 0, 0.5)},
+		{"rgb(51 102 153 / 25%)", RGBA(51, 102, 153, 0.25)},
+		{"hsl(210, 50%, 40%)", RGB(51, 102, 153)},
+		{"hsla(120deg 100% 25% / 0.5)", RGBA(0, 128, 0, 0.5)},
+	} {
+		c, err := ParseColor(tc.s)
+		if err != nil {
+			t.Errorf("%q: %v", tc.s, err)
+			continue
+		}
+		if c != tc.expected {
+			t.Errorf("%q: %+v, expected %+v", tc.s, c, tc.expected)
+		}
+	}
+
+	for _, s := range []string{"", "#12", "#ggg", "reddish", "rgb(1, 2)", "hsl(10, 20, 30)", "cmyk(1, 2, 3, 4)"} {
+		if _, err := ParseColor(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestColorContrast(t *testing.T) {
+	if r := Black.Contrast(White); math.Abs(r-21) > 0.001 {
+		t.Errorf("black on white %f, expected 21", r)
+	}
+	if r := MustColor("#767676").Contrast(White); r < ContrastAA || r > 4.6 {
+		t.Errorf("#767676 on white %f, expected just over %f", r, ContrastAA)
+	}
+	if fg := MustColor("navy").Readable(); fg != White {
+		t.Errorf("readable on navy %v", fg)
+	}
+	if fg := MustColor("yellow").Readable(); fg != Black {
+		t.Errorf("readable on yellow %v", fg)
+	}
+
+	c := MustColor("#336699")
+	if s := c.Lighten(0.2).String(); s != "#6699cc" {
+		t.Errorf("lighten %s", s)
+	}
+	if s := c.Darken(0.2).String(); s != "#1a334d" {
+		t.Errorf("darken %s", s)
+	}
+	if s := Black.Mix(White, 0.5).String(); s != "#808080" {
+		t.Errorf("mix %s", s)
+	}
+	if s := c.Alpha(0.5).String(); s != "rgba(51, 102, 153, 0.5)" {
+		t.Errorf("alpha %s", s)
+	}
+	if p := RGB(255, 0, 0).Palette(3); p[1] != RGB(0, 255, 0) || p[2] != RGB(0, 0, 255) {
+		t.Errorf("palette %v", p)
+	}
+	for _, n := range []int{0, -1} {
+		if c.Shades(n) != nil || c.Scale(White, n) != nil || c.Palette(n) != nil {
+			t.Errorf("colors for n %d", n)
+		}
+	}
+	if s := c.Shades(1); len(s) != 1 {
+		t.Errorf("one shade %v", s)
+	}
+
+	row := Table().Row()
+	if cell := row.CellString("x").BgColor(MustColor("navy")); cell.GetAttr("style") != "background-color:#000080;color:#ffffff" {
+		t.Errorf("cell style %q", cell.GetAttr("style"))
+	}
+	if cell := row.CellString("x").Fg("red").BgColor(White); cell.GetAttr("style") != "color:red;background-color:#ffffff" {
+		t.Errorf("cell style %q", cell.GetAttr("style"))
+	}
+}
@@ -1,21 +1,18 @@
 package html
 
-import "fmt"
-
 // Container is a generic container of HTML elements, like div, body, etc...
 type Container struct {
 	Attributes
 	elements   []Element
 	javaScript map[string]string
+
+	// required are the scripts the elements of the container need, written once by the Document
+	required []*ScriptElement
 }
 
 // WriteContent write all elements in the container
 func (c *Container) WriteContent(tw *TagWriter) {
-	if len(c.javaScript) > 0 {
-		for k, v := range c.javaScript {
-			Script(fmt.Sprintf("function %s() {\n%s\n}\n", k, v)).Write(tw)
-		}
-	}
+	c.writeJavaScript(tw)
 
 	for _, e := range c.elements {
 		e.Write(tw)
@@ -33,3 +30,16 @@ func (c *Container) AddJavaScript(scriptName string, script string) {
 	}
 	c.javaScript[scriptName] += script
 }
+
+// writeJavaScript writes each function added with AddJavaScript, sorted by name,
+// unless the container is in a Document, which writes them in one script
+func (c *Container) writeJavaScript(tw *TagWriter) {
+	if tw.gathered {
+		return
+	}
+	r := newJSRegistry()
+	c.contributeJavaScript(r)
+	for _, name := range r.names {
+		Script(jsFunction(name, r.funcs[name])).Write(tw)
+	}
+}
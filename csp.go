@@ -0,0 +1,144 @@
+package html
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+// Content-Security-Policy source expressions
+const (
+	CSPSelf          = "'self'"
+	CSPNone          = "'none'"
+	CSPUnsafeInline  = "'unsafe-inline'"
+	CSPStrictDynamic = "'strict-dynamic'"
+	// CSPNonce is replaced by 'nonce-...' with the nonce of each render
+	CSPNonce = "'nonce'"
+)
+
+// CSP builds a Content-Security-Policy header
+// Directives are written in the order they were first added
+type CSP struct {
+	directives []string
+	sources    map[string][]string
+	reportOnly bool
+}
+
+// NewCSP creates a strict policy, which only allows resources from the same origin and scripts and styles
+// carrying the nonce of the render. Style attributes are allowed, as elements are styled with them.
+func NewCSP() *CSP {
+	return NewEmptyCSP().
+		Add("default-src", CSPSelf).
+		Add("script-src", CSPNonce).
+		Add("style-src", CSPSelf, CSPNonce).
+		Add("style-src-attr", CSPUnsafeInline).
+		Add("object-src", CSPNone).
+		Add("base-uri", CSPSelf)
+}
+
+// NewEmptyCSP creates a policy with no directives
+func NewEmptyCSP() *CSP {
+	return &CSP{
+		sources: make(map[string][]string),
+	}
+}
+
+// Add adds sources to a directive, such as Add("img-src", CSPSelf, "https://cdn.example.com")
+func (csp *CSP) Add(directive string, sources ...string) *CSP {
+	directive = strings.ToLower(strings.TrimSpace(directive))
+	if _, ok := csp.sources[directive]; !ok {
+		csp.directives = append(csp.directives, directive)
+	}
+	for _, s := range sources {
+		if !containsString(csp.sources[directive], s) {
+			csp.sources[directive] = append(csp.sources[directive], s)
+		}
+	}
+	if csp.sources[directive] == nil {
+		csp.sources[directive] = []string{}
+	}
+	return csp
+}
+
+// Set replaces the sources of a directive
+func (csp *CSP) Set(directive string, sources ...string) *CSP {
+	directive = strings.ToLower(strings.TrimSpace(directive))
+	delete(csp.sources, directive)
+	for i, d := range csp.directives {
+		if d == directive {
+			csp.directives = append(csp.directives[:i], csp.directives[i+1:]...)
+			break
+		}
+	}
+	return csp.Add(directive, sources...)
+}
+
+// ReportTo asks the browser to report violations to url
+func (csp *CSP) ReportTo(url string) *CSP {
+	return csp.Set("report-uri", url)
+}
+
+// ReportOnly sends the policy as Content-Security-Policy-Report-Only, so violations are reported but not blocked
+func (csp *CSP) ReportOnly() *CSP {
+	csp.reportOnly = true
+	return csp
+}
+
+// HeaderName returns the name of the header the policy is sent in
+func (csp *CSP) HeaderName() string {
+	if csp.reportOnly {
+		return "Content-Security-Policy-Report-Only"
+	}
+	return "Content-Security-Policy"
+}
+
+// Header returns the value of the header, with CSPNonce replaced by nonce
+func (csp *CSP) Header(nonce string) string {
+	parts := make([]string, 0, len(csp.directives))
+	for _, d := range csp.directives {
+		sources := make([]string, 0, len(csp.sources[d])+1)
+		sources = append(sources, d)
+		for _, s := range csp.sources[d] {
+			if s == CSPNonce {
+				if len(nonce) == 0 {
+					continue
+				}
+				s = "'nonce-" + nonce + "'"
+			}
+			sources = append(sources, s)
+		}
+		parts = append(parts, strings.Join(sources, " "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// SetHeader sets the policy header on the response
+func (csp *CSP) SetHeader(w http.ResponseWriter, nonce string) {
+	w.Header().Set(csp.HeaderName(), csp.Header(nonce))
+}
+
+// NewNonce returns a new random nonce
+func NewNonce() string {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// SetCSP sets the Content-Security-Policy of the document
+// Each Render creates a new nonce, sends it in the policy header and adds it to every script and style tag
+func (doc *Document) SetCSP(csp *CSP) {
+	doc.csp = csp
+}
+
+// SetNonce sets the nonce added to every script and style tag written by the TagWriter
+func (tw *TagWriter) SetNonce(nonce string) {
+	tw.nonce = nonce
+}
+
+// Nonce returns the nonce of the render, or "" if there is none
+func (tw *TagWriter) Nonce() string {
+	return tw.nonce
+}
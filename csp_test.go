@@ -0,0 +1,59 @@
+package html
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCSP(t *testing.T) {
+	doc := NewDocument()
+	doc.SetCSP(NewCSP().Add("img-src", CSPSelf, "data:"))
+	doc.AddCSS(CSS("p { color: red }"))
+	style := NewStyle("cell", StyleDef{Key: "padding", Value: "0"})
+	style.AddElement("td")
+	doc.AddStyle(style)
+
+	form := Form(NewLink("/save")).SetName("edit").ValidateFilled("title", "Title is required")
+	button := Button("Go")
+	button.OnClick("alert('go');")
+	doc.Body().Add(form, button)
+
+	w := httptest.NewRecorder()
+	doc.Render(w)
+	html := w.Body.String()
+
+	header := w.Header().Get("Content-Security-Policy")
+	m := regexp.MustCompile(`script-src 'nonce-([^']+)'`).FindStringSubmatch(header)
+	if m == nil || !strings.Contains(header, "img-src 'self' data:") {
+		t.Fatalf("header %q", header)
+	}
+	nonce := m[1]
+
+	for _, tag := range []string{"<script", "<style"} {
+		if n, withNonce := strings.Count(html, tag), strings.Count(html, tag+` nonce="`+nonce+`"`)+strings.Count(html, tag+` type="text/css" nonce="`+nonce+`"`); n == 0 || n != withNonce {
+			t.Errorf("%d of %d %s tags have the nonce:\n%s", withNonce, n, tag, html)
+		}
+	}
+	for _, s := range []string{"onclick=", "onsubmit="} {
+		if strings.Contains(html, s) {
+			t.Errorf("inline handler %s in:\n%s", s, html)
+		}
+	}
+	for _, s := range []string{`document.forms["edit"].addEventListener("submit"`, `.addEventListener("click"`} {
+		if !strings.Contains(html, s) {
+			t.Errorf("missing listener %s in:\n%s", s, html)
+		}
+	}
+
+	// each render has a new nonce, and the validation is not repeated
+	w2 := httptest.NewRecorder()
+	doc.Render(w2)
+	if w2.Header().Get("Content-Security-Policy") == header {
+		t.Error("nonce was reused")
+	}
+	if n := strings.Count(w2.Body.String(), "return true;"); n != 1 {
+		t.Errorf("validation written with %d returns", n)
+	}
+}
@@ -0,0 +1,186 @@
+package html
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrNoCSRFSession is returned for a request with no CSRF session, which has not been through CSRF.Handler
+var ErrNoCSRFSession = errors.New("csrf: request has no session, it must be served by CSRF.Handler")
+
+// csrfKeySize is the least number of bytes of a CSRF key
+const csrfKeySize = 32
+
+// Defaults for CSRF
+const (
+	CSRFFieldName  = "csrf_token"
+	CSRFHeaderName = "X-CSRF-Token"
+	CSRFCookieName = "csrf_session"
+)
+
+// csrfSessionKey is the request context key of the session secret set by CSRF.Handler
+type csrfSessionKey struct{}
+
+// CSRF protects forms against cross-site request forgery. Handler gives each browser session a secret in a cookie,
+// and rejects POST, PUT, PATCH and DELETE requests which do not carry a token made from that secret.
+// Render writes a Document with a token in every POST form as a hidden field.
+type CSRF struct {
+	key        []byte
+	fieldName  string
+	headerName string
+	cookieName string
+	onError    http.Handler
+}
+
+// NewCSRF creates CSRF protection, key is a server secret of at least 32 random bytes used to sign the tokens
+// It panics if the key is shorter
+func NewCSRF(key []byte) *CSRF {
+	if len(key) < csrfKeySize {
+		panic("csrf: key must be at least 32 bytes")
+	}
+	return &CSRF{
+		key:        key,
+		fieldName:  CSRFFieldName,
+		headerName: CSRFHeaderName,
+		cookieName: CSRFCookieName,
+		onError: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "invalid CSRF token", http.StatusForbidden)
+		}),
+	}
+}
+
+// SetFieldName sets the name of the hidden form field holding the token
+func (c *CSRF) SetFieldName(name string) *CSRF {
+	c.fieldName = name
+	return c
+}
+
+// SetCookieName sets the name of the cookie holding the session secret
+func (c *CSRF) SetCookieName(name string) *CSRF {
+	c.cookieName = name
+	return c
+}
+
+// SetErrorHandler sets the handler called for rejected requests, which by default responds 403 Forbidden
+func (c *CSRF) SetErrorHandler(h http.Handler) *CSRF {
+	c.onError = h
+	return c
+}
+
+// Handler checks the token of state changing requests before passing them to next.
+// The token is read from the form field, or the X-CSRF-Token header for scripts.
+func (c *CSRF) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := c.session(r)
+		if len(session) == 0 {
+			session = randomToken()
+			http.SetCookie(w, &http.Cookie{
+				Name:     c.cookieName,
+				Value:    session,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   r.TLS != nil,
+				SameSite: http.SameSiteLaxMode,
+			})
+			r = r.WithContext(context.WithValue(r.Context(), csrfSessionKey{}, session))
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		default:
+			token := r.Header.Get(c.headerName)
+			if len(token) == 0 {
+				token = r.PostFormValue(c.fieldName)
+			}
+			if !c.valid(session, token) {
+				c.onError.ServeHTTP(w, r)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Token returns a new token for the session of the request, or ErrNoCSRFSession if the request has no session
+// Each token is different, but all are valid for the session
+func (c *CSRF) Token(r *http.Request) (string, error) {
+	session := c.session(r)
+	if len(session) == 0 {
+		return "", ErrNoCSRFSession
+	}
+	nonce := randomToken()
+	return nonce + "." + c.sign(session, nonce), nil
+}
+
+// Render renders the document with a new token for the session of the request in each POST form.
+// The token is only given to the TagWriter, so the document can be cached and rendered for many requests at once.
+// It returns ErrNoCSRFSession, without writing anything, if the request has no session, as the forms could not be submitted.
+func (c *CSRF) Render(w http.ResponseWriter, r *http.Request, doc *Document) error {
+	token, err := c.Token(r)
+	if err != nil {
+		return err
+	}
+	tw := NewTagWriter(w)
+	tw.SetCSRFToken(c.fieldName, token)
+	doc.render(w, tw)
+	return nil
+}
+
+// Protect adds a token for the session of the request to each POST form of the document, like Render.
+// The token is kept in the document, so it must be a Document built for this request, never one which is cached or shared.
+// It returns ErrNoCSRFSession if the request has no session.
+func (c *CSRF) Protect(doc *Document, r *http.Request) error {
+	token, err := c.Token(r)
+	if err != nil {
+		return err
+	}
+	doc.csrfField = c.fieldName
+	doc.csrfToken = token
+	return nil
+}
+
+// session returns the session secret of the request, from the context if Handler has just created it
+func (c *CSRF) session(r *http.Request) string {
+	if s, ok := r.Context().Value(csrfSessionKey{}).(string); ok {
+		return s
+	}
+	if cookie, err := r.Cookie(c.cookieName); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+func (c *CSRF) sign(session string, nonce string) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(session + "|" + nonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (c *CSRF) valid(session string, token string) bool {
+	dot := strings.IndexByte(token, '.')
+	if dot < 0 || len(session) == 0 {
+		return false
+	}
+	return hmac.Equal([]byte(token[dot+1:]), []byte(c.sign(session, token[:dot])))
+}
+
+// randomToken returns 32 random bytes, base64 encoded
+func randomToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// SetCSRFToken adds a hidden field called name with the token to each POST form written by the TagWriter
+func (tw *TagWriter) SetCSRFToken(name string, token string) {
+	tw.csrfField = name
+	tw.csrfToken = token
+}
@@ -0,0 +1,121 @@
+package html
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCSRF(t *testing.T) {
+	csrf := NewCSRF([]byte("0123456789abcdef0123456789abcdef"))
+	handler := csrf.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc := NewDocument()
+		doc.Body().Add(Form(NewLink("/save")).MethodPOST(), Form(NewLink("/search")))
+		if err := csrf.Protect(doc, r); err != nil {
+			t.Fatal(err)
+		}
+		doc.Render(w)
+	}))
+
+	// the first request creates the session and renders the token in the POST form only
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != CSRFCookieName || !cookies[0].HttpOnly {
+		t.Fatalf("cookies %v", cookies)
+	}
+	tokens := regexp.MustCompile(`name="csrf_token" type="hidden" value="([^"]+)"`).FindAllStringSubmatch(w.Body.String(), -1)
+	if len(tokens) != 1 {
+		t.Fatalf("%d tokens in:\n%s", len(tokens), w.Body.String())
+	}
+	token := tokens[0][1]
+
+	post := func(token string, cookie bool) int {
+		r := httptest.NewRequest("POST", "/save", strings.NewReader(url.Values{CSRFFieldName: {token}}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie {
+			r.AddCookie(cookies[0])
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+	if code := post(token, true); code != http.StatusOK {
+		t.Errorf("valid token: status %d", code)
+	}
+	if code := post("", true); code != http.StatusForbidden {
+		t.Errorf("missing token: status %d", code)
+	}
+	if code := post(token[:len(token)-2]+"xx", true); code != http.StatusForbidden {
+		t.Errorf("bad token: status %d", code)
+	}
+	if code := post(token, false); code != http.StatusForbidden {
+		t.Errorf("token of another session: status %d", code)
+	}
+
+	r := httptest.NewRequest("DELETE", "/item", nil)
+	r.AddCookie(cookies[0])
+	r.Header.Set(CSRFHeaderName, token)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("header token: status %d", w.Code)
+	}
+}
+
+func TestCSRFRenderSharedDocument(t *testing.T) {
+	csrf := NewCSRF([]byte("0123456789abcdef0123456789abcdef"))
+	doc := NewDocument()
+	doc.Body().Add(Form(NewLink("/save")).MethodPOST())
+	handler := csrf.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := csrf.Render(w, r, doc); err != nil {
+			t.Error(err)
+		}
+	}))
+
+	// each session gets its own token from the one document, which keeps none
+	tokenRE := regexp.MustCompile(`name="csrf_token" type="hidden" value="([^"]+)"`)
+	var tokens []string
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		m := tokenRE.FindStringSubmatch(w.Body.String())
+		if m == nil {
+			t.Fatalf("no token in:\n%s", w.Body.String())
+		}
+		tokens = append(tokens, m[1])
+	}
+	if tokens[0] == tokens[1] || len(doc.csrfToken) > 0 {
+		t.Errorf("tokens %v, document token %q", tokens, doc.csrfToken)
+	}
+	if html := renderString(doc.Write); strings.Contains(html, "csrf_token") {
+		t.Errorf("token rendered without a request:\n%s", html)
+	}
+}
+
+func TestCSRFMisuse(t *testing.T) {
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("no panic for a short key")
+			}
+		}()
+		NewCSRF(nil)
+	}()
+
+	csrf := NewCSRF([]byte("0123456789abcdef0123456789abcdef"))
+	r := httptest.NewRequest("GET", "/", nil)
+	if _, err := csrf.Token(r); err != ErrNoCSRFSession {
+		t.Errorf("token error %v", err)
+	}
+	if err := csrf.Protect(NewDocument(), r); err != ErrNoCSRFSession {
+		t.Errorf("protect error %v", err)
+	}
+	w := httptest.NewRecorder()
+	if err := csrf.Render(w, r, NewDocument()); err != ErrNoCSRFSession || w.Body.Len() > 0 {
+		t.Errorf("render error %v, wrote %q", err, w.Body.String())
+	}
+}
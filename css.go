@@ -2,25 +2,41 @@ package html
 
 type styleWriter func(tw *TagWriter)
 
-// Style describes an individual CSS style, which contians a set of
-// associations (element, class or id) and set of key/values which
-// describe individual styles
+// CSSData is a block of CSS text, which is rendered into a <style> block as written
 type CSSData struct {
 	Attributes
 	css string
+
+	// sheet is the parsed CSS, err is set if it could not be parsed cleanly
+	sheet *Stylesheet
+	err   error
 }
 
-// NewStyle will create a Style object, identified by name.
-// An optional list of StyleDef can be passed to add individual styles, or Add() can be called later
-// The Style will need to be added to the Styles list to be rendered
+// CSS will create a CSSData object from CSS text.
+// The text is parsed when it is created, Err returns any problems found
+// The CSSData will need to be added to a CSS container to be rendered
 func CSS(css string) *CSSData {
+	sheet, err := ParseCSS(css)
 	return &CSSData{
-		css: css,
+		css:   css,
+		sheet: sheet,
+		err:   err,
 	}
 }
 
+// Err returns the CSSErrors found parsing the CSS, or nil if it is valid
+func (css *CSSData) Err() error {
+	return css.err
+}
+
+// Stylesheet returns the parsed CSS
+func (css *CSSData) Stylesheet() *Stylesheet {
+	return css.sheet
+}
+
+// WriteContent writes the CSS, a </style> inside the CSS is escaped so it can not end the style block
 func (css *CSSData) WriteContent(tw *TagWriter) {
-	tw.WriteString(css.css)
+	tw.WriteString(escapeCloseTag(css.css, "style"))
 }
 
 type CSSElement struct {
@@ -52,10 +68,25 @@ func (s *CSSElement) WriteContent(tw *TagWriter) {
 }
 
 // Add CSS to the CSS container
+// The CSS is added even if it is not valid, as a browser will skip what it can not parse, see Err
 func (s *CSSElement) Add(css *CSSData) {
 	s.css = append(s.css, css)
 }
 
+// Err returns the CSSErrors found parsing the CSS of the container, or nil if it is all valid
+func (s *CSSElement) Err() error {
+	var errs CSSErrors
+	for _, css := range s.css {
+		if e, ok := css.err.(CSSErrors); ok {
+			errs = append(errs, e...)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
 var (
 	styleSep      = []byte(", ")
 	styleIndent   = []byte("    ")
@@ -85,10 +116,14 @@ type Style struct {
 	// element:   table
 	// class:     .center  or table.center
 	// id:        #table1
+	// selector:  ul.menu > li:hover
 	associations []string
 
 	// styles key: value
 	styles map[string]string
+
+	// keys is the order in which styles were added
+	keys []string
 }
 
 // NewStyle will create a Style object, identified by name.
@@ -106,6 +141,9 @@ func NewStyle(name string, defs ...StyleDef) *Style {
 // Add will add individual styles
 func (style *Style) Add(defs ...StyleDef) {
 	for _, def := range defs {
+		if _, ok := style.styles[def.Key]; !ok {
+			style.keys = append(style.keys, def.Key)
+		}
 		style.styles[def.Key] = def.Value
 	}
 }
@@ -132,11 +170,11 @@ func (s *Style) Write(tw *TagWriter) {
 		multiAssoc = true
 	}
 	s.writeStyle(tw, func(tw *TagWriter) {
-		for k, v := range s.styles {
+		for _, k := range s.keys {
 			tw.Write(styleIndent)
 			tw.WriteString(k)
 			tw.Write(styleBreak) // :
-			tw.WriteString(v)
+			tw.WriteString(escapeCloseTag(s.styles[k], "style"))
 			tw.Write(styleComplete) // ;\n
 		}
 	})
@@ -145,8 +183,28 @@ func (s *Style) Write(tw *TagWriter) {
 // Add Class to this style
 func (s *Style) AddClass(c *Class) {
 	if c != nil {
-		s.associations = append(s.associations, "."+c.Name)
+		s.associations = append(s.associations, "."+CSSEscape(c.Name))
+	}
+}
+
+// AddElement associates the style with all elements of a type, such as "table"
+func (s *Style) AddElement(element string) error {
+	return s.AddSelector(Select(element))
+}
+
+// AddID associates the style with the element with the given id
+func (s *Style) AddID(id string) error {
+	return s.AddSelector(SelectID(id))
+}
+
+// AddSelector associates the style with the elements matched by sel
+// The selector is not added if it is not valid
+func (s *Style) AddSelector(sel *Selector) error {
+	if err := sel.Err(); err != nil {
+		return err
 	}
+	s.associations = append(s.associations, sel.String())
+	return nil
 }
 
 func (s *Style) writeStyle(tw *TagWriter, sw styleWriter) {
@@ -155,10 +213,18 @@ func (s *Style) writeStyle(tw *TagWriter, sw styleWriter) {
 	tw.Write(styleClose)
 }
 
+// styleRule is a Style or a StyleGroup
+type styleRule interface {
+	Write(tw *TagWriter)
+}
+
 // StyleElement contains a map of styles
 type StyleElement struct {
 	Attributes
 	styles map[string]*Style
+
+	// rules are the styles and groups in the order they were added, so later rules override earlier ones
+	rules []styleRule
 }
 
 // NewStyles will create a container to contain Style objects
@@ -173,7 +239,7 @@ func NewStyles() *StyleElement {
 // Write all styles
 func (s *StyleElement) Write(tw *TagWriter) {
 	// nothing to do
-	if len(s.styles) == 0 {
+	if len(s.rules) == 0 {
 		return
 	}
 	tw.WriteTag(TagStyle, s)
@@ -181,21 +247,51 @@ func (s *StyleElement) Write(tw *TagWriter) {
 
 // Write each style
 func (s *StyleElement) WriteContent(tw *TagWriter) {
-	for k, v := range s.styles {
-		tw.Comment("Style", k)
-		v.Write(tw)
+	for _, rule := range s.rules {
+		if style, ok := rule.(*Style); ok {
+			tw.Comment("Style", style.name)
+		}
+		rule.Write(tw)
 	}
 }
 
 // Add a Style to the Styles container
+// A style with the same name as an existing style replaces it, keeping its place
 func (styles *StyleElement) Add(style *Style) {
+	if old, ok := styles.styles[style.name]; ok {
+		for i, rule := range styles.rules {
+			if rule == old {
+				styles.rules[i] = style
+			}
+		}
+	} else {
+		styles.rules = append(styles.rules, style)
+	}
 	styles.styles[style.name] = style
 }
 
+// AddGroup adds a StyleGroup (@media, @supports) to the Styles container, after any styles already added
+// The group is not added if it is not valid
+func (styles *StyleElement) AddGroup(group *StyleGroup) error {
+	if err := group.Err(); err != nil {
+		return err
+	}
+	for _, rule := range styles.rules {
+		if rule == group {
+			return nil
+		}
+	}
+	styles.rules = append(styles.rules, group)
+	return nil
+}
+
 // Class is an association between Styles and elements
 type Class struct {
 	// Name is the name of the Class
 	Name string
+
+	// style is the style of a scoped class
+	style *Style
 }
 
 // NewClass creates a new CSS Class with name as the class name
@@ -217,3 +313,16 @@ func StyleBackgroundColor(color string) StyleDef {
 		Value: color,
 	}
 }
+
+// StyleBackground defines a background color from a Color
+func StyleBackground(color Color) StyleDef {
+	return StyleBackgroundColor(color.String())
+}
+
+// StyleColor defines a text color from a Color
+func StyleColor(color Color) StyleDef {
+	return StyleDef{
+		Key:   "color",
+		Value: color.String(),
+	}
+}
@@ -1,5 +1,7 @@
 package html
 
+import "testing"
+
 /*func NoTestWrite(t *testing.T) {
 	styles := &Styles{
 		styles: map[string]*Style{
@@ -33,3 +35,61 @@ package html
 	t.Error(b.String())
 }
 */
+
+func TestStyleGroups(t *testing.T) {
+	card := NewClass("card")
+	base := NewStyle("base", StyleDef{Key: "width", Value: "50%"})
+	base.AddClass(card)
+	narrow := NewStyle("narrow", StyleDef{Key: "width", Value: "100%"})
+	narrow.AddClass(card)
+	grid := NewStyle("grid", StyleDef{Key: "display", Value: "grid"})
+	grid.AddClass(card)
+	dark := NewStyle("dark", StyleBackgroundColor("black"))
+	dark.AddClass(card)
+
+	styles := NewStyles()
+	styles.Add(base)
+	if err := styles.AddGroup(Media(MediaAnd(MediaScreen, MediaMaxWidth(600)), narrow).
+		AddGroup(Supports("(display: grid)", grid))); err != nil {
+		t.Fatal(err)
+	}
+	if err := styles.AddGroup(Media(MediaDark, dark)); err != nil {
+		t.Fatal(err)
+	}
+	// replacing a style keeps its place ahead of the groups
+	base = NewStyle("base", StyleDef{Key: "width", Value: "60%"})
+	base.AddClass(card)
+	styles.Add(base)
+
+	expected := `<style type="text/css"><!-- Style base -->
+.card {
+    width: 60%;
+}
+@media screen and (max-width: 600px) {
+.card {
+    width: 100%;
+}
+@supports (display: grid) {
+.card {
+    display: grid;
+}
+}
+}
+@media (prefers-color-scheme: dark) {
+.card {
+    background: black;
+}
+}
+</style>
+`
+	if got := renderString(styles.Write); got != expected {
+		t.Errorf("styles:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	if err := styles.AddGroup(Media("print { body")); err == nil {
+		t.Error("expected error for invalid media query")
+	}
+	if err := styles.AddGroup(Media(MediaPrint).AddGroup(Supports(""))); err == nil {
+		t.Error("expected error for invalid nested group")
+	}
+}
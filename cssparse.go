@@ -0,0 +1,786 @@
+package html
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// CSSError is a problem found parsing CSS, at line and column (1 based)
+type CSSError struct {
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (e *CSSError) Error() string {
+	return fmt.Sprintf("css: %d:%d: %s", e.Line, e.Col, e.Msg)
+}
+
+// CSSErrors is the list of problems found parsing CSS, in the order they were found
+type CSSErrors []*CSSError
+
+func (errs CSSErrors) Error() string {
+	s := make([]string, len(errs))
+	for i, e := range errs {
+		s[i] = e.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// Stylesheet is parsed CSS
+type Stylesheet struct {
+	Rules []*CSSRule
+}
+
+// CSSRule is a style rule, selectors { declarations }, or an at-rule, @keyword prelude { block } or @keyword prelude;
+type CSSRule struct {
+	// Selectors is the selector list of a style rule, empty for at-rules
+	Selectors string
+
+	// AtKeyword is the name of an at-rule without the @, such as media or font-face
+	AtKeyword string
+
+	// Prelude is the text between the at-keyword and the block, such as "screen and (max-width: 600px)"
+	Prelude string
+
+	// Declarations of a style rule, or of an at-rule with a declaration block such as @font-face
+	Declarations []CSSDeclaration
+
+	// Rules nested in a group rule such as @media or @supports
+	Rules []*CSSRule
+
+	// Block is the unparsed content of the block of any other at-rule, such as @keyframes
+	Block string
+
+	// Line and Col are the position of the rule in the source
+	Line int
+	Col  int
+
+	// statement is set for at-rules ending in a semicolon, @import url(x);
+	statement bool
+}
+
+// CSSDeclaration is a single property: value
+type CSSDeclaration struct {
+	Property  string
+	Value     string
+	Important bool
+}
+
+// atRuleContent is the kind of block for known at-rules
+var atRuleContent = map[string]string{
+	"media":               "rules",
+	"supports":            "rules",
+	"container":           "rules",
+	"layer":               "rules",
+	"document":            "rules",
+	"scope":               "rules",
+	"font-face":           "declarations",
+	"page":                "declarations",
+	"property":            "declarations",
+	"counter-style":       "declarations",
+	"font-palette-values": "declarations",
+	"viewport":            "declarations",
+}
+
+// ParseCSS parses a style sheet.  Errors are returned as CSSErrors with their positions.
+// Parsing recovers from errors as a browser does, so the returned Stylesheet holds every rule that could be read
+func ParseCSS(css string) (*Stylesheet, error) {
+	p := &cssParser{src: css}
+	p.tokenize()
+	sheet := &Stylesheet{Rules: p.ruleList(false)}
+	if len(p.errs) > 0 {
+		sort.SliceStable(p.errs, func(i, j int) bool {
+			a, b := p.errs[i], p.errs[j]
+			return a.Line < b.Line || (a.Line == b.Line && a.Col < b.Col)
+		})
+		return sheet, p.errs
+	}
+	return sheet, nil
+}
+
+// String returns the style sheet as CSS
+func (sheet *Stylesheet) String() string {
+	sb := strings.Builder{}
+	for _, rule := range sheet.Rules {
+		rule.write(&sb, false)
+	}
+	return sb.String()
+}
+
+// Minify returns the style sheet as CSS with comments and unneeded whitespace removed
+func (sheet *Stylesheet) Minify() string {
+	sb := strings.Builder{}
+	for _, rule := range sheet.Rules {
+		rule.write(&sb, true)
+	}
+	return sb.String()
+}
+
+// Styles returns the style rules of the sheet as Style objects, at-rules are left out
+// Each Style is named after its selectors and position, so it is unique when added to a StyleElement
+func (sheet *Stylesheet) Styles() []*Style {
+	var styles []*Style
+	for _, rule := range sheet.Rules {
+		if style := rule.Style(); style != nil {
+			styles = append(styles, style)
+		}
+	}
+	return styles
+}
+
+// AddTo adds the rules of the sheet to a StyleElement in order, @media and @supports become StyleGroups
+// Other at-rules can not be represented by a Style, they are left out and reported in the error
+func (sheet *Stylesheet) AddTo(styles *StyleElement) error {
+	var errs CSSErrors
+	for _, rule := range sheet.Rules {
+		if style := rule.Style(); style != nil {
+			styles.Add(style)
+			continue
+		}
+		group, err := rule.styleGroup()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := styles.AddGroup(group); err != nil {
+			errs = append(errs, &CSSError{Line: rule.Line, Col: rule.Col, Msg: err.Error()})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Style returns a style rule as a Style, or nil for an at-rule
+func (rule *CSSRule) Style() *Style {
+	if len(rule.AtKeyword) > 0 {
+		return nil
+	}
+	style := NewStyle(fmt.Sprintf("%s (%d:%d)", rule.Selectors, rule.Line, rule.Col))
+	if sels, err := parseSelectorList(rule.Selectors); err == nil {
+		for _, sel := range sels {
+			style.associations = append(style.associations, sel.text)
+		}
+	}
+	for _, d := range rule.Declarations {
+		value := d.Value
+		if d.Important {
+			value += " !important"
+		}
+		style.Add(StyleDef{Key: d.Property, Value: value})
+	}
+	return style
+}
+
+// styleGroup converts @media and @supports rules into a StyleGroup
+func (rule *CSSRule) styleGroup() (*StyleGroup, *CSSError) {
+	var group *StyleGroup
+	switch rule.AtKeyword {
+	case "media":
+		group = Media(rule.Prelude)
+	case "supports":
+		group = Supports(rule.Prelude)
+	default:
+		return nil, &CSSError{Line: rule.Line, Col: rule.Col, Msg: "@" + rule.AtKeyword + " can not be converted to a Style"}
+	}
+	for _, r := range rule.Rules {
+		if style := r.Style(); style != nil {
+			group.Add(style)
+			continue
+		}
+		g, err := r.styleGroup()
+		if err != nil {
+			return nil, err
+		}
+		group.AddGroup(g)
+	}
+	return group, nil
+}
+
+// String returns the rule as CSS
+func (rule *CSSRule) String() string {
+	sb := strings.Builder{}
+	rule.write(&sb, false)
+	return sb.String()
+}
+
+func (rule *CSSRule) write(sb *strings.Builder, minify bool) {
+	open, indent, colon, end, close := styleOpen, styleIndent, styleBreak, styleComplete, styleClose
+	prelude, value := strings.TrimSpace, strings.TrimSpace
+	if minify {
+		open, indent, colon, end, close = []byte("{"), nil, []byte(":"), []byte(";"), []byte("}")
+		prelude = minifySelectors
+		value = minifyValue
+	}
+
+	if len(rule.AtKeyword) == 0 {
+		sb.WriteString(prelude(rule.Selectors))
+	} else {
+		sb.WriteString("@" + rule.AtKeyword)
+		if len(rule.Prelude) > 0 {
+			sb.WriteString(" " + value(rule.Prelude))
+		}
+		if rule.statement {
+			sb.WriteString(";")
+			if !minify {
+				sb.WriteString("\n")
+			}
+			return
+		}
+	}
+	sb.Write(open)
+	switch {
+	case len(rule.Rules) > 0:
+		for _, r := range rule.Rules {
+			r.write(sb, minify)
+		}
+	case len(rule.Block) > 0:
+		if minify {
+			sb.WriteString(minifyValue(rule.Block))
+		} else {
+			sb.WriteString(rule.Block)
+			sb.WriteString("\n")
+		}
+	default:
+		for i, d := range rule.Declarations {
+			if minify && i > 0 {
+				sb.Write(end)
+			}
+			sb.Write(indent)
+			sb.WriteString(d.Property)
+			sb.Write(colon)
+			sb.WriteString(value(d.Value))
+			if d.Important {
+				sb.WriteString(" !important")
+			}
+			if !minify {
+				sb.Write(end)
+			}
+		}
+	}
+	sb.Write(close)
+}
+
+// minifyValue collapses whitespace outside of strings, and removes it after commas and around braces
+func minifyValue(s string) string {
+	return minifySpace(s, ",{};")
+}
+
+// minifySelectors collapses whitespace outside of strings, and removes it around commas and combinators
+func minifySelectors(s string) string {
+	return minifySpace(s, ",>+~")
+}
+
+// minifySpace collapses runs of whitespace to a single space, or removes them entirely next to any of the punctuation characters
+func minifySpace(s string, punct string) string {
+	sb := strings.Builder{}
+	var quote byte
+	space := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			sb.WriteByte(c)
+			if c == '\\' && i+1 < len(s) {
+				i++
+				sb.WriteByte(s[i])
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if isCSSSpace(c) {
+			space = true
+			continue
+		}
+		if space {
+			if last := sb.Len() - 1; last >= 0 && strings.IndexByte(punct, sb.String()[last]) < 0 && strings.IndexByte(punct, c) < 0 {
+				sb.WriteByte(' ')
+			}
+			space = false
+		}
+		if c == '"' || c == '\'' {
+			quote = c
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+type cssTokenType int
+
+const (
+	tokEOF cssTokenType = iota
+	tokWhitespace
+	tokIdent
+	tokFunction
+	tokAtKeyword
+	tokHash
+	tokString
+	tokURL
+	tokDelim
+	tokNumber
+	tokPercentage
+	tokDimension
+	tokCDO
+	tokCDC
+	tokColon
+	tokSemicolon
+	tokComma
+	tokOpenSquare
+	tokCloseSquare
+	tokOpenParen
+	tokCloseParen
+	tokOpenCurly
+	tokCloseCurly
+)
+
+// cssToken is a token, value is the name of idents, functions, at-keywords and hashes
+// start and end are byte offsets into the source
+type cssToken struct {
+	typ   cssTokenType
+	value string
+	start int
+	end   int
+}
+
+// cssParser tokenizes and parses CSS following the CSS Syntax Module Level 3
+type cssParser struct {
+	src    string
+	tokens []cssToken
+	pos    int
+	errs   CSSErrors
+
+	// lines are the offsets of the start of each line
+	lines []int
+}
+
+func (p *cssParser) errorf(offset int, format string, args ...interface{}) {
+	line, col := p.position(offset)
+	p.errs = append(p.errs, &CSSError{Line: line, Col: col, Msg: fmt.Sprintf(format, args...)})
+}
+
+// position returns the line and column of an offset
+func (p *cssParser) position(offset int) (int, int) {
+	if p.lines == nil {
+		p.lines = []int{0}
+		for i := 0; i < len(p.src); i++ {
+			if p.src[i] == '\n' {
+				p.lines = append(p.lines, i+1)
+			}
+		}
+	}
+	line := sort.Search(len(p.lines), func(i int) bool { return p.lines[i] > offset }) - 1
+	return line + 1, utf8.RuneCountInString(p.src[p.lines[line]:offset]) + 1
+}
+
+// tokenize splits the source into tokens, comments are dropped
+func (p *cssParser) tokenize() {
+	s := p.src
+	i := 0
+	for i < len(s) {
+		start := i
+		c := s[i]
+		tok := cssToken{start: start}
+		switch {
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				p.errorf(start, "unterminated comment")
+				i = len(s)
+			} else {
+				i += end + 4
+			}
+			continue
+		case isCSSSpace(c):
+			for i < len(s) && isCSSSpace(s[i]) {
+				i++
+			}
+			tok.typ = tokWhitespace
+		case c == '"' || c == '\'':
+			tok.typ = tokString
+			tok.value, i = p.consumeString(i)
+		case c == '#':
+			if i+1 < len(s) && (isNameChar(s[i+1]) || startsEscape(s, i+1)) {
+				tok.typ = tokHash
+				tok.value, i = consumeName(s, i+1)
+			} else {
+				tok.typ = tokDelim
+				i++
+			}
+		case startsNumber(s, i):
+			tok.typ = tokNumber
+			i = consumeNumber(s, i)
+			if c := byteAt(s, i); c == '%' {
+				tok.typ = tokPercentage
+				i++
+			} else if startsIdent(s, i) {
+				tok.typ = tokDimension
+				tok.value, i = consumeName(s, i)
+			}
+		case startsIdent(s, i):
+			tok.value, i = consumeName(s, i)
+			tok.typ = tokIdent
+			if byteAt(s, i) == '(' {
+				i++
+				tok.typ = tokFunction
+				if strings.EqualFold(tok.value, "url") {
+					i = p.consumeURL(&tok, i)
+				}
+			}
+		case c == '@' && startsIdent(s, i+1):
+			tok.typ = tokAtKeyword
+			tok.value, i = consumeName(s, i+1)
+		case strings.HasPrefix(s[i:], "<!--"):
+			tok.typ = tokCDO
+			i += 4
+		case strings.HasPrefix(s[i:], "-->"):
+			tok.typ = tokCDC
+			i += 3
+		default:
+			i++
+			tok.typ = map[byte]cssTokenType{
+				':': tokColon, ';': tokSemicolon, ',': tokComma,
+				'[': tokOpenSquare, ']': tokCloseSquare, '(': tokOpenParen, ')': tokCloseParen,
+				'{': tokOpenCurly, '}': tokCloseCurly,
+			}[c]
+			if tok.typ == tokEOF {
+				tok.typ = tokDelim
+				if c == '\\' {
+					p.errorf(start, "invalid escape")
+				}
+			}
+		}
+		tok.end = i
+		p.tokens = append(p.tokens, tok)
+	}
+	p.tokens = append(p.tokens, cssToken{typ: tokEOF, start: len(s), end: len(s)})
+}
+
+// consumeString consumes a quoted string starting at i, returning its value and the offset after it
+func (p *cssParser) consumeString(i int) (string, int) {
+	s := p.src
+	quote := s[i]
+	start := i
+	sb := strings.Builder{}
+	for i++; i < len(s); i++ {
+		switch c := s[i]; c {
+		case quote:
+			return sb.String(), i + 1
+		case '\n':
+			p.errorf(start, "unterminated string")
+			return sb.String(), i
+		case '\\':
+			if i+1 < len(s) && s[i+1] == '\n' {
+				i++
+				continue
+			}
+			sp := &selectorParser{s: s, pos: i + 1}
+			sb.WriteString(sp.escape())
+			i = sp.pos - 1
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	p.errorf(start, "unterminated string")
+	return sb.String(), i
+}
+
+// consumeURL consumes the rest of url( ... ), i is after the (
+// A quoted url is left as a function token, an unquoted one becomes a url token
+func (p *cssParser) consumeURL(tok *cssToken, i int) int {
+	s := p.src
+	j := i
+	for j < len(s) && isCSSSpace(s[j]) {
+		j++
+	}
+	if c := byteAt(s, j); c == '"' || c == '\'' {
+		return i
+	}
+	tok.typ = tokURL
+	for ; j < len(s); j++ {
+		switch c := s[j]; {
+		case c == ')':
+			tok.value = strings.TrimSpace(s[i:j])
+			return j + 1
+		case c == '\\':
+			j++
+		case c == '"' || c == '\'' || c == '(':
+			p.errorf(tok.start, "invalid character %q in url()", c)
+		}
+	}
+	p.errorf(tok.start, "unterminated url()")
+	return j
+}
+
+func byteAt(s string, i int) byte {
+	if i < 0 || i >= len(s) {
+		return 0
+	}
+	return s[i]
+}
+
+// startsEscape returns true if there is a valid escape at i
+func startsEscape(s string, i int) bool {
+	return byteAt(s, i) == '\\' && i+1 < len(s) && s[i+1] != '\n'
+}
+
+// startsIdent returns true if an identifier starts at i
+func startsIdent(s string, i int) bool {
+	switch c := byteAt(s, i); {
+	case c == '-':
+		n := byteAt(s, i+1)
+		return isNameStart(n) || n == '-' || startsEscape(s, i+1)
+	case c == '\\':
+		return startsEscape(s, i)
+	default:
+		return i < len(s) && isNameStart(c)
+	}
+}
+
+// startsNumber returns true if a number starts at i
+func startsNumber(s string, i int) bool {
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	c := byteAt(s, i)
+	if c == '+' || c == '-' {
+		i++
+		c = byteAt(s, i)
+	}
+	return isDigit(c) || (c == '.' && isDigit(byteAt(s, i+1)))
+}
+
+// consumeNumber returns the offset after the number at i
+func consumeNumber(s string, i int) int {
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	if c := byteAt(s, i); c == '+' || c == '-' {
+		i++
+	}
+	for isDigit(byteAt(s, i)) {
+		i++
+	}
+	if byteAt(s, i) == '.' && isDigit(byteAt(s, i+1)) {
+		for i++; isDigit(byteAt(s, i)); i++ {
+		}
+	}
+	if c := byteAt(s, i); c == 'e' || c == 'E' {
+		j := i + 1
+		if c := byteAt(s, j); c == '+' || c == '-' {
+			j++
+		}
+		if isDigit(byteAt(s, j)) {
+			for i = j; isDigit(byteAt(s, i)); i++ {
+			}
+		}
+	}
+	return i
+}
+
+// consumeName consumes a name at i, resolving escapes
+func consumeName(s string, i int) (string, int) {
+	sp := &selectorParser{s: s, pos: i}
+	name := sp.ident()
+	return name, sp.pos
+}
+
+func (p *cssParser) next() cssToken {
+	tok := p.tokens[p.pos]
+	if tok.typ != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *cssParser) peek() cssToken {
+	return p.tokens[p.pos]
+}
+
+func (p *cssParser) skipWhitespace() {
+	for p.peek().typ == tokWhitespace {
+		p.pos++
+	}
+}
+
+// ruleList parses rules until the end of input, or for a nested list up to, but not including, the closing }
+func (p *cssParser) ruleList(nested bool) []*CSSRule {
+	var rules []*CSSRule
+	for {
+		tok := p.next()
+		switch tok.typ {
+		case tokEOF:
+			return rules
+		case tokWhitespace, tokSemicolon:
+		case tokCDO, tokCDC:
+			if nested {
+				p.errorf(tok.start, "unexpected %s", p.src[tok.start:tok.end])
+			}
+		case tokCloseCurly:
+			if nested {
+				p.pos--
+				return rules
+			}
+			p.errorf(tok.start, "unexpected }")
+		case tokAtKeyword:
+			if rule := p.atRule(tok); rule != nil {
+				rules = append(rules, rule)
+			}
+		default:
+			p.pos--
+			if rule := p.styleRule(); rule != nil {
+				rules = append(rules, rule)
+			}
+		}
+	}
+}
+
+// component consumes a component value, if it opens a block or function the whole block is consumed
+func (p *cssParser) component(tok cssToken) {
+	var close cssTokenType
+	switch tok.typ {
+	case tokOpenCurly:
+		close = tokCloseCurly
+	case tokOpenSquare:
+		close = tokCloseSquare
+	case tokOpenParen, tokFunction:
+		close = tokCloseParen
+	default:
+		return
+	}
+	for {
+		t := p.next()
+		switch t.typ {
+		case close:
+			return
+		case tokEOF:
+			p.errorf(tok.start, "missing %s", map[cssTokenType]string{tokCloseCurly: "}", tokCloseSquare: "]", tokCloseParen: ")"}[close])
+			return
+		}
+		p.component(t)
+	}
+}
+
+// closeBlock consumes the } that closes the block opened by open
+func (p *cssParser) closeBlock(open cssToken) {
+	if t := p.next(); t.typ != tokCloseCurly {
+		p.errorf(open.start, "missing }")
+	}
+}
+
+// styleRule parses selectors { declarations }
+func (p *cssParser) styleRule() *CSSRule {
+	p.skipWhitespace()
+	start := p.peek()
+	for {
+		tok := p.next()
+		switch tok.typ {
+		case tokEOF:
+			p.errorf(start.start, "expected { after %q", strings.TrimSpace(p.src[start.start:]))
+			return nil
+		case tokOpenCurly:
+			rule := &CSSRule{Selectors: strings.TrimSpace(p.src[start.start:tok.start])}
+			rule.Line, rule.Col = p.position(start.start)
+			rule.Declarations = p.declarationList()
+			p.closeBlock(tok)
+			if _, err := parseSelectorList(rule.Selectors); err != nil {
+				p.errorf(start.start, "%v", err)
+				return nil
+			}
+			return rule
+		default:
+			p.component(tok)
+		}
+	}
+}
+
+// atRule parses @keyword prelude; or @keyword prelude { block }
+func (p *cssParser) atRule(at cssToken) *CSSRule {
+	rule := &CSSRule{AtKeyword: strings.ToLower(at.value)}
+	rule.Line, rule.Col = p.position(at.start)
+	for {
+		tok := p.next()
+		switch tok.typ {
+		case tokEOF, tokSemicolon, tokCloseCurly:
+			if tok.typ == tokCloseCurly {
+				p.pos--
+			}
+			rule.Prelude = strings.TrimSpace(p.src[at.end:tok.start])
+			rule.statement = true
+			return rule
+		case tokOpenCurly:
+			rule.Prelude = strings.TrimSpace(p.src[at.end:tok.start])
+			switch atRuleContent[strings.TrimPrefix(rule.AtKeyword, "-webkit-")] {
+			case "rules":
+				rule.Rules = p.ruleList(true)
+				p.closeBlock(tok)
+			case "declarations":
+				rule.Declarations = p.declarationList()
+				p.closeBlock(tok)
+			default:
+				p.pos--
+				p.component(p.next())
+				end := p.tokens[p.pos-1]
+				if end.typ == tokCloseCurly {
+					rule.Block = strings.TrimSpace(p.src[tok.end:end.start])
+				} else {
+					rule.Block = strings.TrimSpace(p.src[tok.end:])
+				}
+			}
+			return rule
+		default:
+			p.component(tok)
+		}
+	}
+}
+
+// declarationList parses property: value; pairs up to, but not including, the closing }
+func (p *cssParser) declarationList() []CSSDeclaration {
+	var decls []CSSDeclaration
+	for {
+		tok := p.next()
+		switch tok.typ {
+		case tokEOF:
+			return decls
+		case tokCloseCurly:
+			p.pos--
+			return decls
+		case tokWhitespace, tokSemicolon:
+		case tokAtKeyword:
+			// nested at-rules are not part of the Style model
+			p.atRule(tok)
+		case tokIdent:
+			p.skipWhitespace()
+			if colon := p.peek(); colon.typ != tokColon {
+				p.errorf(colon.start, "expected : after %q", tok.value)
+				p.skipDeclaration()
+				continue
+			}
+			p.next()
+			start := p.peek().start
+			end := p.skipDeclaration()
+			value := strings.TrimSpace(p.src[start:end])
+			if len(value) == 0 {
+				p.errorf(tok.start, "missing value for %q", tok.value)
+				continue
+			}
+			decls = append(decls, parseDeclaration(tok.value, value))
+		default:
+			p.errorf(tok.start, "unexpected %q in declaration list", p.src[tok.start:tok.end])
+			p.pos--
+			p.skipDeclaration()
+		}
+	}
+}
+
+// skipDeclaration consumes up to the next ; or } and returns the offset where it stopped
+func (p *cssParser) skipDeclaration() int {
+	for {
+		tok := p.peek()
+		switch tok.typ {
+		case tokEOF, tokSemicolon, tokCloseCurly:
+			return tok.start
+		}
+		p.component(p.next())
+	}
+}
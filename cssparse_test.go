@@ -0,0 +1,125 @@
+package html
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCSS(t *testing.T) {
+	sheet, err := ParseCSS(`@charset "utf-8";
+/* comment { } */
+body, td.total > a:hover { color: red; background: url(x.png) no-repeat; --Main-Color: #fff !important }
+@media screen and (max-width: 600px) {
+  .card { width: 100% }
+  @supports (display: grid) { .card { display: grid } }
+}
+@font-face { font-family: "Open Sans"; src: url("/f.woff2") }
+@keyframes spin { from { transform: rotate(0) } to { transform: rotate(360deg) } }
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Rules) != 5 {
+		t.Fatalf("%d rules, expected 5", len(sheet.Rules))
+	}
+
+	charset := sheet.Rules[0]
+	if charset.AtKeyword != "charset" || charset.Prelude != `"utf-8"` || !charset.statement {
+		t.Errorf("charset %+v", charset)
+	}
+
+	body := sheet.Rules[1]
+	if body.Selectors != "body, td.total > a:hover" || body.Line != 3 || body.Col != 1 {
+		t.Errorf("style rule %+v", body)
+	}
+	expected := []CSSDeclaration{
+		{Property: "color", Value: "red"},
+		{Property: "background", Value: "url(x.png) no-repeat"},
+		{Property: "--Main-Color", Value: "#fff", Important: true},
+	}
+	if len(body.Declarations) != len(expected) {
+		t.Fatalf("declarations %+v", body.Declarations)
+	}
+	for i, d := range expected {
+		if body.Declarations[i] != d {
+			t.Errorf("declaration %+v, expected %+v", body.Declarations[i], d)
+		}
+	}
+
+	media := sheet.Rules[2]
+	if media.Prelude != "screen and (max-width: 600px)" || len(media.Rules) != 2 || media.Rules[1].AtKeyword != "supports" {
+		t.Errorf("media %+v", media)
+	}
+	if fontFace := sheet.Rules[3]; len(fontFace.Declarations) != 2 {
+		t.Errorf("font-face %+v", fontFace)
+	}
+	if keyframes := sheet.Rules[4]; !strings.HasPrefix(keyframes.Block, "from {") {
+		t.Errorf("keyframes %+v", keyframes)
+	}
+
+	// the printed sheet parses to the same rules
+	again, err := ParseCSS(sheet.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != sheet.String() {
+		t.Errorf("round trip:\n%s\nexpected:\n%s", again.String(), sheet.String())
+	}
+}
+
+func TestParseCSSErrors(t *testing.T) {
+	for _, tc := range []struct {
+		css      string
+		expected string
+	}{
+		{"p { color red }", "css: 1:11: expected : after \"color\""},
+		{"p {\n  color: ;\n}", "css: 2:3: missing value for \"color\""},
+		{"p { color: red", "css: 1:3: missing }"},
+		{"p { content: \"abc\n }", "css: 1:14: unterminated string"},
+		{"/* open", "css: 1:1: unterminated comment"},
+		{"}\np { }", "css: 1:1: unexpected }"},
+		{"p..x { }", `css: 1:1: selector "p..x": offset 2: missing class name`},
+		{"p { color: red }\ndiv", `css: 2:1: expected { after "div"`},
+		{"a { b: url(x\"y) }", "css: 1:8: invalid character '\"' in url()"},
+	} {
+		_, err := ParseCSS(tc.css)
+		errs, ok := err.(CSSErrors)
+		if !ok || len(errs) == 0 {
+			t.Errorf("%q: expected error", tc.css)
+			continue
+		}
+		if got := errs[0].Error(); got != tc.expected {
+			t.Errorf("%q: error %q, expected %q", tc.css, got, tc.expected)
+		}
+	}
+}
+
+func TestCSSData(t *testing.T) {
+	doc := NewDocument()
+	doc.AddCSS(CSS(`p { color: red }`))
+	doc.AddCSS(CSS(`p { content: "</style><script>alert(1)</script>" }`))
+	if err := doc.CheckCSS(); err != nil {
+		t.Error(err)
+	}
+	bad := CSS(`p { color red }`)
+	doc.AddCSS(bad)
+	if bad.Err() == nil || doc.CheckCSS() == nil || doc.CheckCSS().Error() != bad.Err().Error() {
+		t.Errorf("expected error, got %v and %v", bad.Err(), doc.CheckCSS())
+	}
+
+	html := renderString(doc.Write)
+	if strings.Count(strings.ToLower(html), "</style") != 1 {
+		t.Errorf("</style> was not escaped: %s", html)
+	}
+
+	styles := NewStyles()
+	sheet := CSS(`td { padding: 0 } @media print { td { display: none } } @import "x.css";`).Stylesheet()
+	err := sheet.AddTo(styles)
+	if errs, ok := err.(CSSErrors); !ok || len(errs) != 1 || !strings.Contains(errs[0].Msg, "@import") {
+		t.Errorf("AddTo error %v", err)
+	}
+	expected := "td {\n    padding: 0;\n}\n@media print {\ntd {\n    display: none;\n}\n}\n"
+	if got := renderString(styles.WriteContent); !strings.Contains(got, expected) {
+		t.Errorf("styles:\n%s\nexpected:\n%s", got, expected)
+	}
+}
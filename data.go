@@ -0,0 +1,37 @@
+package html
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONData creates a JSON data island, <script type="application/json" id="...">, holding v serialized as JSON.
+// Scripts read it with JSON.parse(document.getElementById(id).textContent), instead of data being pasted into code.
+// <, > and & are written as \u003c, \u003e and \u0026, and U+2028 and U+2029 as \u2028 and \u2029,
+// so the data can not end the script early or break the page.
+func JSONData(id string, v interface{}) (*ScriptElement, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(true)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("json data %q: %v", id, err)
+	}
+	s := &ScriptElement{}
+	s.AddAttr("type", "application/json")
+	s.AddAttr("id", id)
+	s.Add(Raw(string(bytes.TrimSpace(b.Bytes()))))
+	return s, nil
+}
+
+// JSString returns s as a quoted JavaScript string literal, "...", which is safe to write inside a script.
+// Quotes, backslashes and control characters are escaped, as are <, >, &, U+2028 and U+2029.
+// The literal contains double quotes, so it must be HTML escaped to be used in an attribute, as AddAttr values are.
+func JSString(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(true)
+	// a string always encodes
+	enc.Encode(s)
+	return string(bytes.TrimSpace(b.Bytes()))
+}
@@ -0,0 +1,40 @@
+package html
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONData(t *testing.T) {
+	value := map[string]string{"note": "</script><script>alert(1)</script> '\"&\u2028"}
+	s, err := JSONData("report-data", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := renderString(s.Write)
+	if !strings.HasPrefix(html, `<script id="report-data" type="application/json">`) {
+		t.Errorf("tag %s", html)
+	}
+	content := html[strings.Index(html, ">")+1 : strings.LastIndex(html, "</script>")]
+	if strings.ContainsAny(content, "<>&\u2028") {
+		t.Errorf("unescaped content %s", content)
+	}
+	var back map[string]string
+	if err := json.Unmarshal([]byte(content), &back); err != nil || back["note"] != value["note"] {
+		t.Errorf("round trip %q %v", back, err)
+	}
+
+	if _, err := JSONData("bad", func() {}); err == nil {
+		t.Error("expected error")
+	}
+
+	if got, expected := JSString("say \"hi\" </script>\n\u2029"), `"say \"hi\" \u003c/script\u003e\n\u2029"`; got != expected {
+		t.Errorf("JSString %s, expected %s", got, expected)
+	}
+
+	form := Form(NewLink("/save")).SetName("edit").ValidateFilled("title", `Title "required"`)
+	if js := renderString(form.Write); !strings.Contains(js, `alert("Title \"required\"");`) || !strings.Contains(js, `document.forms["edit"].elements["title"]`) {
+		t.Errorf("validation script:\n%s", js)
+	}
+}
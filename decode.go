@@ -0,0 +1,441 @@
+package html
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldErrorClass is the class of the inline error messages written after form fields, see FormElement.SetErrors
+const FieldErrorClass = "field-error"
+
+// maxMultipartMemory is the memory used to parse multipart forms, larger files are stored on disk
+const maxMultipartMemory = 32 << 20
+
+// FieldErrors are error messages keyed by field name
+type FieldErrors map[string]string
+
+// Error lists the errors sorted by field name
+func (errs FieldErrors) Error() string {
+	names := make([]string, 0, len(errs))
+	for name := range errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		names[i] = name + ": " + errs[name]
+	}
+	return strings.Join(names, "; ")
+}
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// DecodeForm sets the fields of the struct dst points to from submitted form values, using the field names
+// and html struct tags of FormFromStruct. Fields without a value in values are left unchanged, bools too, as a form
+// may not have a field for them. An unchecked checkbox is not submitted, so use FormElement.Bind with the form
+// to clear the bools of its unchecked checkboxes. Empty values set NULL for sql.Null types and nil for pointers.
+// Times are parsed as the values of date, datetime-local and time inputs, in the location of the current value of the field
+// as FormFromStruct formats them in it, so set the field to a time in the location first, UTC is used for a zero time.
+// Values which can not be converted are returned as FieldErrors, the other fields are still set.
+func DecodeForm(values url.Values, dst interface{}) error {
+	return decodeForm(values, nil, nil, dst)
+}
+
+// DecodeRequest parses the form of a request, url encoded or multipart, and decodes it into dst as DecodeForm does
+// Uploaded files are set on fields of type *multipart.FileHeader or []*multipart.FileHeader
+func DecodeRequest(r *http.Request, dst interface{}) error {
+	var files map[string][]*multipart.FileHeader
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(maxMultipartMemory); err != nil {
+			return err
+		}
+		files = r.MultipartForm.File
+	} else if err := r.ParseForm(); err != nil {
+		return err
+	}
+	return decodeForm(r.Form, files, nil, dst)
+}
+
+// decodeForm decodes values and files into dst, bools without a value are cleared if they are in checkboxes
+func decodeForm(values url.Values, files map[string][]*multipart.FileHeader, checkboxes map[string]bool, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("form: decode into %T, expected a pointer to a struct", dst)
+	}
+	rv = rv.Elem()
+
+	errs := FieldErrors{}
+	for _, field := range structFields(rv.Type()) {
+		fv := field.value(rv)
+		switch field.typ {
+		case fileHeaderType:
+			if fh := files[field.name]; len(fh) > 0 {
+				fv.Set(reflect.ValueOf(fh[0]))
+			}
+			continue
+		case fileHeadersType:
+			if fh := files[field.name]; len(fh) > 0 {
+				fv.Set(reflect.ValueOf(fh))
+			}
+			continue
+		}
+
+		vals, ok := values[field.name]
+		if !ok {
+			if field.typ.Kind() == reflect.Bool && checkboxes[field.name] {
+				fv.SetBool(false)
+			}
+			continue
+		}
+		if field.typ.Kind() == reflect.Slice && field.typ.Elem().Kind() != reflect.Uint8 {
+			slice := reflect.MakeSlice(field.typ, len(vals), len(vals))
+			for i, s := range vals {
+				if msg := decodeValue(slice.Index(i), s, field.tag); len(msg) > 0 {
+					errs[field.name] = msg
+				}
+			}
+			fv.Set(slice)
+			continue
+		}
+		s := ""
+		if len(vals) > 0 {
+			s = vals[0]
+		}
+		if msg := decodeValue(fv, s, field.tag); len(msg) > 0 {
+			errs[field.name] = msg
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// decodeValue converts s and sets it on v, returning an error message if s can not be converted
+func decodeValue(v reflect.Value, s string, tag fieldTag) string {
+	s = strings.TrimSpace(s)
+	typ := v.Type()
+
+	if typ.Kind() == reflect.Ptr {
+		if len(s) == 0 {
+			v.Set(reflect.Zero(typ))
+			return ""
+		}
+		p := reflect.New(typ.Elem())
+		if !v.IsNil() {
+			// keep the current value, such as the location of a time
+			p.Elem().Set(v.Elem())
+		}
+		if msg := decodeValue(p.Elem(), s, tag); len(msg) > 0 {
+			return msg
+		}
+		v.Set(p)
+		return ""
+	}
+
+	if typ.Implements(enumType) {
+		return decodeEnum(v, s)
+	}
+	if opts, ok := tag.options["options"]; ok && len(s) > 0 && !containsString(strings.Split(opts, "|"), s) {
+		return "Must be one of " + strings.Join(strings.Split(opts, "|"), ", ")
+	}
+
+	switch typ {
+	case timeType:
+		t, msg := parseFormTime(s, tag.format, v.Interface().(time.Time).Location())
+		if len(msg) == 0 {
+			v.Set(reflect.ValueOf(t))
+		}
+		return msg
+	case reflect.TypeOf(sql.NullTime{}):
+		t, msg := parseFormTime(s, tag.format, v.Interface().(sql.NullTime).Time.Location())
+		if len(msg) == 0 {
+			v.Set(reflect.ValueOf(sql.NullTime{Time: t, Valid: len(s) > 0}))
+		}
+		return msg
+	case reflect.TypeOf(sql.NullString{}):
+		v.Set(reflect.ValueOf(sql.NullString{String: s, Valid: len(s) > 0}))
+		return ""
+	case reflect.TypeOf(sql.NullBool{}), reflect.TypeOf(sql.NullInt64{}), reflect.TypeOf(sql.NullInt32{}), reflect.TypeOf(sql.NullFloat64{}):
+		// the value is the first field, followed by Valid
+		if len(s) == 0 {
+			v.Set(reflect.Zero(typ))
+			return ""
+		}
+		if msg := decodeValue(v.Field(0), s, tag); len(msg) > 0 {
+			return msg
+		}
+		v.Field(1).SetBool(true)
+		return ""
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		switch strings.ToLower(s) {
+		case "true", "on", "1", "yes":
+			v.SetBool(true)
+		case "", "false", "off", "0", "no":
+			v.SetBool(false)
+		default:
+			return "Must be yes or no"
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if len(s) == 0 {
+			v.SetInt(0)
+			break
+		}
+		n, err := strconv.ParseInt(s, 10, typ.Bits())
+		if err != nil {
+			return numberError(err, "Must be a whole number")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if len(s) == 0 {
+			v.SetUint(0)
+			break
+		}
+		n, err := strconv.ParseUint(s, 10, typ.Bits())
+		if err != nil {
+			return numberError(err, "Must be a positive whole number")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if len(s) == 0 {
+			v.SetFloat(0)
+			break
+		}
+		f, err := strconv.ParseFloat(s, typ.Bits())
+		if err != nil {
+			return numberError(err, "Must be a number")
+		}
+		v.SetFloat(f)
+	default:
+		return "Can not be set from a form"
+	}
+	return ""
+}
+
+func numberError(err error, msg string) string {
+	if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+		return "Is out of range"
+	}
+	return msg
+}
+
+// decodeEnum sets v to the value of the Enum whose underlying value is s
+func decodeEnum(v reflect.Value, s string) string {
+	if len(s) == 0 {
+		v.Set(reflect.Zero(v.Type()))
+		return ""
+	}
+	for _, ev := range reflect.Zero(v.Type()).Interface().(Enum).EnumValues() {
+		evv := reflect.ValueOf(ev)
+		if rawValue(evv) == s && evv.Type().ConvertibleTo(v.Type()) {
+			v.Set(evv.Convert(v.Type()))
+			return ""
+		}
+	}
+	return "Must be one of the listed values"
+}
+
+// parseFormTime parses the value of a date, datetime-local, time, month or week input, or a time in the layout of the tag
+// in loc. A week is its Monday
+func parseFormTime(s string, layout string, loc *time.Location) (time.Time, string) {
+	if len(s) == 0 {
+		return time.Time{}, ""
+	}
+	layouts := []string{datetimeLayout, datetimeLayout + ":05", dateLayout, timeLayout, timeLayout + ":05", monthLayout, time.RFC3339}
+	if len(layout) > 0 {
+		layouts = append(layouts, layout)
+	}
+	for _, l := range layouts {
+		if t, err := time.ParseInLocation(l, s, loc); err == nil {
+			return t, ""
+		}
+	}
+	var year, week int
+	if n, err := fmt.Sscanf(s, "%4d-W%2d", &year, &week); err == nil && n == 2 && week >= 1 && week <= 53 {
+		// the Monday of the ISO week, week 1 contains January 4th
+		jan4 := time.Date(year, 1, 4, 0, 0, 0, 0, loc)
+		monday := jan4.AddDate(0, 0, -(int(jan4.Weekday())+6)%7)
+		return monday.AddDate(0, 0, 7*(week-1)), ""
+	}
+	return time.Time{}, "Must be a date"
+}
+
+// SetValues sets the fields of the form to submitted values, so a form which failed validation
+// renders again with the user's input. Password and file inputs are not set.
+func (f *FormElement) SetValues(values url.Values) *FormElement {
+	Walk(f, func(e Element) bool {
+		name := e.GetAttr("name")
+		vals, ok := values[name]
+		if e == Element(f) || len(name) == 0 {
+			return true
+		}
+		switch t := e.(type) {
+		case *InputElement:
+			switch t.GetAttr("type") {
+			case "password", "file", "submit", "reset", "image", "button":
+			case "checkbox", "radio":
+				t.AddAttr("checked", strconv.FormatBool(containsString(vals, t.GetAttr("value"))))
+			default:
+				if ok && len(vals) > 0 {
+					t.AddTextAttr("value", vals[0])
+				}
+			}
+		case *CheckboxElement:
+			t.SetChecked(containsString(vals, t.GetAttr("value")))
+		case *TextAreaElement:
+			if ok && len(vals) > 0 {
+				t.SetDefault(html.EscapeString(vals[0]))
+			}
+		case *FormSelectElement:
+			if ok {
+				t.SetValue(vals...)
+			}
+		}
+		return true
+	})
+	return f
+}
+
+// SetErrors sets error messages for fields, keyed by field name. Each message is written after the field,
+// in a span of class FieldErrorClass, and the field is marked aria-invalid. nil clears the errors.
+func (f *FormElement) SetErrors(errs map[string]string) *FormElement {
+	f.errors = errs
+	return f
+}
+
+// Bind decodes submitted values into the struct dst points to and checks them with the validators of the form.
+// Unlike DecodeForm, bools are cleared when the form has an unchecked checkbox for them.
+// If either fails the form is set to render again with the values and the errors, which are returned as FieldErrors.
+func (f *FormElement) Bind(values url.Values, dst interface{}) error {
+	errs := FieldErrors{}
+	if err := decodeForm(values, nil, f.checkboxes(), dst); err != nil {
+		fe, ok := err.(FieldErrors)
+		if !ok {
+			return err
+		}
+		for k, v := range fe {
+			errs[k] = v
+		}
+	}
+	for k, v := range f.Validate(values) {
+		if _, ok := errs[k]; !ok {
+			errs[k] = v
+		}
+	}
+	if len(errs) == 0 {
+		f.SetErrors(nil)
+		return nil
+	}
+	f.SetValues(values).SetErrors(errs)
+	return errs
+}
+
+// checkboxes returns the names of the checkboxes of the form
+func (f *FormElement) checkboxes() map[string]bool {
+	names := make(map[string]bool)
+	Walk(f, func(e Element) bool {
+		switch t := e.(type) {
+		case *CheckboxElement:
+			names[t.GetAttr("name")] = true
+		case *InputElement:
+			if t.GetAttr("type") == "checkbox" {
+				names[t.GetAttr("name")] = true
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// fieldErrors are the errors of the form being written
+type fieldErrors struct {
+	form    string
+	errors  map[string]string
+	written map[string]bool
+}
+
+// id returns the id of the error message of a field
+func (fe *fieldErrors) id(name string) string {
+	return fe.form + "-" + strings.ReplaceAll(name, ".", "-") + "-error"
+}
+
+// hasFieldError reports if the element is a field with an error
+func (tw *TagWriter) hasFieldError(tag HtmlTag, e Element) bool {
+	if tw.fieldErrors == nil || tag == TagForm || tag == TagOption {
+		return false
+	}
+	name := e.GetAttr("name")
+	_, ok := tw.fieldErrors.errors[name]
+	return ok && len(name) > 0
+}
+
+// fieldAttrs returns the serialized attributes of an element, with those marking a field with an error:
+// aria-invalid, and the id of the error added to any aria-describedby of the element, such as its help text
+func (tw *TagWriter) fieldAttrs(tag HtmlTag, e Element) string {
+	if !tw.hasFieldError(tag, e) {
+		return e.GetAttrs()
+	}
+	ae, ok := e.(interface{ attributes() *Attributes })
+	if !ok {
+		return e.GetAttrs()
+	}
+	a := ae.attributes().clone()
+	described := tw.fieldErrors.id(e.GetAttr("name"))
+	if d := a.GetAttr("aria-describedby"); len(d) > 0 {
+		described = d + " " + described
+	}
+	a.AddAttr("aria-describedby", described)
+	a.AddTextAttr("aria-invalid", "true")
+	return a.GetAttrs()
+}
+
+// writeFieldError writes the error message of a field after it, once
+func (tw *TagWriter) writeFieldError(tag HtmlTag, e Element) {
+	if !tw.hasFieldError(tag, e) {
+		return
+	}
+	name := e.GetAttr("name")
+	if tw.fieldErrors.written[name] {
+		return
+	}
+	tw.writeNamedFieldError(name)
+}
+
+// holdFieldError stops the error of the field being written after its inputs, so a group of inputs
+// can write it once after the group with writeNamedFieldError. It reports if the field has an error.
+func (tw *TagWriter) holdFieldError(name string) bool {
+	if tw.fieldErrors == nil || tw.fieldErrors.written[name] {
+		return false
+	}
+	if _, ok := tw.fieldErrors.errors[name]; !ok {
+		return false
+	}
+	tw.fieldErrors.written[name] = true
+	return true
+}
+
+// writeNamedFieldError writes the error message of a field
+func (tw *TagWriter) writeNamedFieldError(name string) {
+	tw.fieldErrors.written[name] = true
+	span := Span(Text(tw.fieldErrors.errors[name]))
+	span.AddClassName(FieldErrorClass)
+	span.AddAttr("id", tw.fieldErrors.id(name))
+	span.AddAttr("role", "alert")
+	span.Write(tw)
+}
@@ -0,0 +1,133 @@
+package html
+
+import (
+	"bytes"
+	"database/sql"
+	"mime/multipart"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeForm(t *testing.T) {
+	var ticket testTicket
+	ticket.Urgent = true
+	err := DecodeForm(url.Values{
+		"ID":           {"7"},
+		"Title":        {" Fix it "},
+		"Estimate":     {"1.5"},
+		"Due":          {"2024-03-01"},
+		"Closed":       {""},
+		"Owner":        {"ann"},
+		"Priority":     {"2"},
+		"Status":       {"open"},
+		"Address.City": {"Oslo"},
+	}, &ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := testTicket{
+		ID:       7,
+		Title:    "Fix it",
+		Estimate: 1.5,
+		Urgent:   true, // without the form, a missing bool may not have been on the page
+		Due:      time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		Owner:    sql.NullString{String: "ann", Valid: true},
+		Priority: 2,
+		Status:   "open",
+		Address:  testAddress{City: "Oslo"},
+	}
+	if ticket != expected {
+		t.Errorf("decoded %+v, expected %+v", ticket, expected)
+	}
+
+	err = DecodeForm(url.Values{"ID": {"x"}, "Estimate": {"1e999"}, "Priority": {"9"}, "Status": {"lost"}, "Due": {"soon"}}, &ticket)
+	errs, ok := err.(FieldErrors)
+	if !ok {
+		t.Fatalf("error %v", err)
+	}
+	for k, v := range map[string]string{
+		"ID":       "Must be a whole number",
+		"Estimate": "Is out of range",
+		"Priority": "Must be one of the listed values",
+		"Status":   "Must be one of open, closed",
+		"Due":      "Must be a date",
+	} {
+		if errs[k] != v {
+			t.Errorf("%s: error %q, expected %q", k, errs[k], v)
+		}
+	}
+
+	if err := DecodeForm(url.Values{}, ticket); err == nil {
+		t.Error("expected error decoding into a struct value")
+	}
+}
+
+func TestDecodeRequest(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("Name", "report")
+	fw, _ := mw.CreateFormFile("File", "r.csv")
+	fw.Write([]byte("a,b"))
+	mw.Close()
+
+	r := httptest.NewRequest("POST", "/upload", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	var upload struct {
+		Name string
+		File *multipart.FileHeader
+	}
+	if err := DecodeRequest(r, &upload); err != nil {
+		t.Fatal(err)
+	}
+	if upload.Name != "report" || upload.File == nil || upload.File.Filename != "r.csv" {
+		t.Errorf("upload %+v", upload)
+	}
+}
+
+func TestFormBind(t *testing.T) {
+	var ticket testTicket
+	form, err := FormFromStruct(NewLink("/ticket"), &ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	form.SetName("ticket")
+
+	values := url.Values{"Title": {`<b>"x"</b>`}, "Estimate": {"lots"}, "Address.City": {"Oslo"}, "Status": {"closed"}}
+	err = form.Bind(values, &ticket)
+	errs, ok := err.(FieldErrors)
+	if !ok || errs["Estimate"] != "Must be a number" || len(errs) != 1 {
+		t.Fatalf("errors %v", err)
+	}
+
+	html := renderString(form.Write)
+	for _, s := range []string{
+		`name="Estimate" size="10" step="any" type="number" value="lots"`,
+		`<input aria-describedby="ticket-Estimate-error" aria-invalid="true" id=`,
+		`<span class="field-error" id="ticket-Estimate-error" role="alert">Must be a number`,
+		`value="&lt;b&gt;&#34;x&#34;&lt;/b&gt;"`,
+		`<option selected value="closed">`,
+	} {
+		if !strings.Contains(html, s) {
+			t.Errorf("missing %s in:\n%s", s, html)
+		}
+	}
+	if strings.Contains(html, "<b>") {
+		t.Errorf("user input was not escaped:\n%s", html)
+	}
+
+	values = url.Values{"Title": {"Fine"}, "Address.City": {"Oslo"}}
+	ticket.Urgent = true
+	if err := form.Bind(values, &ticket); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if ticket.Urgent {
+		t.Error("the unchecked checkbox did not clear Urgent")
+	}
+	if html := renderString(form.Write); strings.Contains(html, FieldErrorClass) {
+		t.Errorf("errors not cleared:\n%s", html)
+	}
+}
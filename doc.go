@@ -43,6 +43,20 @@ type Document struct {
 	version Version
 	head    *HeadElement
 	body    *BodyElement
+	csp     *CSP
+
+	// scripts are written once, at the end of the body or in the head
+	scripts []*ScriptElement
+
+	// scriptPlace is where the JavaScript of the elements is written
+	scriptPlace ScriptPlace
+
+	// scheme is the class of the color scheme on the html tag, see SetColorScheme
+	scheme string
+
+	// csrfField and csrfToken are added to POST forms, see CSRF.Protect
+	csrfField string
+	csrfToken string
 }
 
 type bufferWriter struct {
@@ -80,7 +94,15 @@ func (doc *Document) Body() *BodyElement {
 
 // Render will write the HTML document to the supplied io.Writer
 func (doc *Document) Render(w http.ResponseWriter) {
-	tw := NewTagWriter(w)
+	doc.render(w, NewTagWriter(w))
+}
+
+// render writes the document to w with tw, see CSRF.Render
+func (doc *Document) render(w http.ResponseWriter, tw *TagWriter) {
+	if doc.csp != nil {
+		tw.SetNonce(NewNonce())
+		doc.csp.SetHeader(w, tw.Nonce())
+	}
 	//	switch doc.version {
 	//	case HTML4:
 	tw.WriteString("<!DOCTYPE html>")
@@ -105,8 +127,16 @@ func (doc *Document) Write(tw *TagWriter) {
 
 // Write writes the HTML head/styles/body
 func (doc *Document) WriteContent(tw *TagWriter) {
+	doc.addScopedStyles()
+	doc.gatherScripts()
+	gathered := tw.gathered
+	tw.gathered = true
+	if len(doc.csrfToken) > 0 {
+		tw.SetCSRFToken(doc.csrfField, doc.csrfToken)
+	}
 	doc.head.Write(tw)
 	doc.body.Write(tw)
+	tw.gathered = gathered
 }
 
 // AddStyle will add a style into the document
@@ -114,7 +144,18 @@ func (doc *Document) AddStyle(style *Style) {
 	doc.head.styles.Add(style)
 }
 
+// AddStyleGroup will add a group of styles, wrapped in @media or @supports, into the document
+func (doc *Document) AddStyleGroup(group *StyleGroup) error {
+	return doc.head.styles.AddGroup(group)
+}
+
 // AddCSS will add a CSS into the document
+// CSS which could not be parsed cleanly is still added, see CSSData.Err and CheckCSS
 func (doc *Document) AddCSS(css *CSSData) {
 	doc.head.css.Add(css)
 }
+
+// CheckCSS returns the CSSErrors found parsing the CSS added with AddCSS, or nil if it is all valid
+func (doc *Document) CheckCSS() error {
+	return doc.head.css.Err()
+}
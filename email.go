@@ -0,0 +1,394 @@
+package html
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	emailLineLength = 76
+	crlf            = "\r\n"
+)
+
+// Email composes an RFC 5322 multipart message around a Document
+// The Document is sent as text/html, with a text/plain alternative rendered from the same tree
+type Email struct {
+	doc *Document
+
+	from    string
+	to      []string
+	cc      []string
+	bcc     []string
+	subject string
+	date    time.Time
+	headers map[string]string
+
+	// inline are parts referenced by the html through cid: urls, keyed by image src
+	inline map[string]*emailPart
+
+	// inlineOrder keeps the inline parts in the order they were embedded
+	inlineOrder []string
+
+	attachments []*emailPart
+}
+
+// emailPart is a single attached or embedded file
+type emailPart struct {
+	filename    string
+	contentType string
+	contentID   string
+	data        []byte
+}
+
+// mimePart is a rendered MIME entity, headers and encoded body
+type mimePart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// NewEmail creates a new Email that will send doc
+func NewEmail(doc *Document) *Email {
+	return &Email{
+		doc:     doc,
+		headers: make(map[string]string),
+		inline:  make(map[string]*emailPart),
+	}
+}
+
+// SetFrom sets the From address, either "user@host" or "Name <user@host>"
+func (m *Email) SetFrom(addr string) *Email {
+	m.from = addr
+	return m
+}
+
+// AddTo adds one or more To recipients
+func (m *Email) AddTo(addrs ...string) *Email {
+	m.to = append(m.to, addrs...)
+	return m
+}
+
+// AddCc adds one or more Cc recipients
+func (m *Email) AddCc(addrs ...string) *Email {
+	m.cc = append(m.cc, addrs...)
+	return m
+}
+
+// AddBcc adds one or more Bcc recipients.  They are only used by Send and never written into the message
+func (m *Email) AddBcc(addrs ...string) *Email {
+	m.bcc = append(m.bcc, addrs...)
+	return m
+}
+
+// SetSubject sets the Subject.  If not set, the Document title is used
+func (m *Email) SetSubject(subject string) *Email {
+	m.subject = subject
+	return m
+}
+
+// SetDate sets the Date header.  If not set, the time the message is written is used
+func (m *Email) SetDate(date time.Time) *Email {
+	m.date = date
+	return m
+}
+
+// SetHeader sets an additional message header, such as Reply-To
+func (m *Email) SetHeader(key string, value string) *Email {
+	m.headers[textproto.CanonicalMIMEHeaderKey(key)] = value
+	return m
+}
+
+// Attach adds a file as an attachment, the content type is taken from the file extension using Mimes
+func (m *Email) Attach(filename string, data []byte) *Email {
+	m.attachments = append(m.attachments, &emailPart{
+		filename:    filename,
+		contentType: MimeTypeOf(filename),
+		data:        data,
+	})
+	return m
+}
+
+// Embed sends data as an inline part for every Image in the Document with a src of src
+// The Image src is replaced with a cid: url when the message is written
+func (m *Email) Embed(src string, data []byte) *Email {
+	filename := src
+	if i := strings.IndexAny(filename, "?#"); i >= 0 {
+		filename = filename[:i]
+	}
+	filename = filename[strings.LastIndex(filename, "/")+1:]
+
+	contentType := MimeTypeOf(filename)
+	if contentType == "application/octet-stream" {
+		contentType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	}
+
+	if _, ok := m.inline[src]; !ok {
+		m.inlineOrder = append(m.inlineOrder, src)
+	}
+	m.inline[src] = &emailPart{
+		filename:    filename,
+		contentType: contentType,
+		data:        data,
+	}
+	return m
+}
+
+// Recipients returns the addresses of all To, Cc and Bcc recipients
+func (m *Email) Recipients() ([]string, error) {
+	var rcpts []string
+	for _, list := range [][]string{m.to, m.cc, m.bcc} {
+		addrs, err := parseAddresses(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range addrs {
+			rcpts = append(rcpts, a.Address)
+		}
+	}
+	return rcpts, nil
+}
+
+// Send delivers the message through the SMTP server at addr, host:port.  auth may be nil
+func (m *Email) Send(addr string, auth smtp.Auth) error {
+	from, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return fmt.Errorf("email: from: %v", err)
+	}
+	rcpts, err := m.Recipients()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		return err
+	}
+	return smtp.SendMail(addr, auth, from.Address, rcpts, buf.Bytes())
+}
+
+// WriteTo writes the complete message, headers and body, to w
+func (m *Email) WriteTo(w io.Writer) (int64, error) {
+	header, err := m.header()
+	if err != nil {
+		return 0, err
+	}
+	body := m.body()
+	header = append(header, "Content-Type: "+body.header.Get("Content-Type"))
+
+	var buf bytes.Buffer
+	for _, h := range header {
+		buf.WriteString(h)
+		buf.WriteString(crlf)
+	}
+	buf.WriteString(crlf)
+	buf.Write(body.body)
+	return buf.WriteTo(w)
+}
+
+// header returns the message headers in the order they are written
+func (m *Email) header() ([]string, error) {
+	var header []string
+
+	from, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return nil, fmt.Errorf("email: from: %v", err)
+	}
+	header = append(header, "From: "+from.String())
+
+	for _, h := range []struct {
+		key  string
+		list []string
+	}{{"To", m.to}, {"Cc", m.cc}} {
+		if len(h.list) == 0 {
+			continue
+		}
+		addrs, err := parseAddresses(h.list)
+		if err != nil {
+			return nil, err
+		}
+		s := make([]string, len(addrs))
+		for i, a := range addrs {
+			s[i] = a.String()
+		}
+		header = append(header, h.key+": "+strings.Join(s, ", "))
+	}
+
+	subject := m.subject
+	if len(subject) == 0 {
+		subject = m.doc.Head().GetTitle()
+	}
+	header = append(header, "Subject: "+mime.QEncoding.Encode("utf-8", subject))
+
+	date := m.date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	header = append(header, "Date: "+date.Format(time.RFC1123Z))
+	header = append(header, "Message-ID: <"+randomHex(16)+"@"+m.domain()+">")
+
+	keys := make([]string, 0, len(m.headers))
+	for k := range m.headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		header = append(header, k+": "+mime.QEncoding.Encode("utf-8", m.headers[k]))
+	}
+
+	header = append(header, "MIME-Version: 1.0")
+	return header, nil
+}
+
+// body renders the MIME tree:
+//
+//	multipart/mixed            (only with attachments)
+//	  multipart/alternative
+//	    text/plain
+//	    multipart/related      (only with embedded images)
+//	      text/html
+//	      inline images
+//	  attachments
+func (m *Email) body() mimePart {
+	html := m.html()
+	related := []mimePart{html}
+	for _, src := range m.inlineOrder {
+		// only images which are in the Document have a content id
+		if part := m.inline[src]; len(part.contentID) > 0 {
+			related = append(related, part.mimePart("inline"))
+		}
+	}
+	if len(related) > 1 {
+		html = multipartBody("related", related)
+	}
+
+	body := multipartBody("alternative", []mimePart{
+		textPart("text/plain", PlainText(m.doc)),
+		html,
+	})
+
+	if len(m.attachments) > 0 {
+		parts := []mimePart{body}
+		for _, a := range m.attachments {
+			parts = append(parts, a.mimePart("attachment"))
+		}
+		body = multipartBody("mixed", parts)
+	}
+	return body
+}
+
+// html renders the Document with embedded images pointing at their cid: parts
+func (m *Email) html() mimePart {
+	var images []*ImageElement
+	Walk(m.doc, func(e Element) bool {
+		if img, ok := e.(*ImageElement); ok {
+			if _, ok := m.inline[img.GetAttr("src")]; ok {
+				images = append(images, img)
+			}
+		}
+		return true
+	})
+	for _, img := range images {
+		src := img.GetAttr("src")
+		part := m.inline[src]
+		if len(part.contentID) == 0 {
+			part.contentID = "part" + getUniqueId() + "." + randomHex(8) + "@" + m.domain()
+		}
+		img.AddAttr("src", "cid:"+part.contentID)
+		defer img.AddAttr("src", src)
+	}
+
+	var buf bytes.Buffer
+	m.doc.IoRender(&buf)
+	return textPart("text/html", buf.String())
+}
+
+// domain returns the domain of the From address, used for Message-ID and Content-ID
+func (m *Email) domain() string {
+	if a, err := mail.ParseAddress(m.from); err == nil {
+		if i := strings.LastIndex(a.Address, "@"); i >= 0 {
+			return a.Address[i+1:]
+		}
+	}
+	return "localhost"
+}
+
+// mimePart encodes the file as base64 with the given disposition, inline or attachment
+func (p *emailPart) mimePart(disposition string) mimePart {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Type", mime.FormatMediaType(p.contentType, map[string]string{"name": p.filename}))
+	h.Set("Content-Transfer-Encoding", "base64")
+	h.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": p.filename}))
+	if len(p.contentID) > 0 {
+		h.Set("Content-Id", "<"+p.contentID+">")
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(p.data)
+	var buf bytes.Buffer
+	for len(encoded) > emailLineLength {
+		buf.WriteString(encoded[:emailLineLength])
+		buf.WriteString(crlf)
+		encoded = encoded[emailLineLength:]
+	}
+	buf.WriteString(encoded)
+	buf.WriteString(crlf)
+	return mimePart{header: h, body: buf.Bytes()}
+}
+
+// textPart encodes text as quoted-printable utf-8
+func textPart(contentType string, text string) mimePart {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Type", contentType+"; charset=utf-8")
+	h.Set("Content-Transfer-Encoding", "quoted-printable")
+
+	var buf bytes.Buffer
+	qp := quotedprintable.NewWriter(&buf)
+	qp.Write([]byte(text))
+	qp.Close()
+	return mimePart{header: h, body: buf.Bytes()}
+}
+
+// multipartBody combines parts into a multipart entity of the given subtype
+func multipartBody(subtype string, parts []mimePart) mimePart {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for _, p := range parts {
+		w, _ := mw.CreatePart(p.header)
+		w.Write(p.body)
+	}
+	mw.Close()
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Type", mime.FormatMediaType("multipart/"+subtype, map[string]string{"boundary": mw.Boundary()}))
+	return mimePart{header: h, body: buf.Bytes()}
+}
+
+func parseAddresses(list []string) ([]*mail.Address, error) {
+	var addrs []*mail.Address
+	for _, s := range list {
+		a, err := mail.ParseAddressList(s)
+		if err != nil {
+			return nil, fmt.Errorf("email: %q: %v", s, err)
+		}
+		addrs = append(addrs, a...)
+	}
+	return addrs, nil
+}
+
+// randomHex returns n random bytes as a hex string
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
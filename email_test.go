@@ -0,0 +1,159 @@
+package html
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func testEmail() *Email {
+	doc := NewDocument()
+	doc.Head().AddTitle("Weekly Report")
+	doc.Body().Add(Heading(1, Text("Report")), Image("/img/logo.png"))
+
+	return NewEmail(doc).
+		SetFrom("Reports <reports@example.com>").
+		AddTo("alice@example.com", "Bob <bob@example.com>").
+		Embed("/img/logo.png", []byte("\x89PNG\r\n\x1a\nfake")).
+		Attach("data.csv", []byte("a,b\n1,2\n"))
+}
+
+func TestEmailWriteTo(t *testing.T) {
+	var b bytes.Buffer
+	if _, err := testEmail().WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := mail.ReadMessage(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := msg.Header.Get("Subject"); s != "Weekly Report" {
+		t.Errorf("Subject %q", s)
+	}
+	if s := msg.Header.Get("To"); s != `<alice@example.com>, "Bob" <bob@example.com>` {
+		t.Errorf("To %q", s)
+	}
+
+	// collect the leaf parts by content type
+	parts := make(map[string]*multipart.Part)
+	bodies := make(map[string]string)
+	var walk func(contentType string, r io.Reader)
+	walk = func(contentType string, r io.Reader) {
+		mt, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(mt, "multipart/") {
+			return
+		}
+		parts[mt] = nil
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			ct := p.Header.Get("Content-Type")
+			mt, _, _ := mime.ParseMediaType(ct)
+			if strings.HasPrefix(mt, "multipart/") {
+				walk(ct, p)
+				continue
+			}
+			b, _ := ioutil.ReadAll(p)
+			parts[mt] = p
+			bodies[mt] = string(b)
+		}
+	}
+	walk(msg.Header.Get("Content-Type"), msg.Body)
+
+	for _, mt := range []string{"multipart/mixed", "multipart/alternative", "multipart/related", "text/plain", "text/html", "image/png", "text/csv"} {
+		if _, ok := parts[mt]; !ok {
+			t.Errorf("missing %s part", mt)
+		}
+	}
+	if !strings.Contains(bodies["text/plain"], "Report\r\n======") {
+		t.Errorf("text/plain %q", bodies["text/plain"])
+	}
+	cid := strings.Trim(parts["image/png"].Header.Get("Content-Id"), "<>")
+	if !strings.Contains(bodies["text/html"], `src="cid:`+cid+`"`) {
+		t.Errorf("text/html does not reference %s: %q", cid, bodies["text/html"])
+	}
+	if csv, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(bodies["text/csv"])); string(csv) != "a,b\n1,2\n" {
+		t.Errorf("text/csv %q", csv)
+	}
+	if parts["text/csv"].FileName() != "data.csv" {
+		t.Errorf("attachment filename %q", parts["text/csv"].FileName())
+	}
+}
+
+func TestEmailSend(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer l.Close()
+
+	// a minimal SMTP server that accepts a single message
+	rcpts := make(chan []string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+		var to []string
+
+		reply("220 localhost")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				to = append(to, strings.Trim(strings.TrimSpace(line)[8:], "<>"))
+				reply("250 ok")
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 go ahead")
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+				}
+				reply("250 ok")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				rcpts <- to
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	if err := testEmail().AddBcc("audit@example.com").Send(l.Addr().String(), nil); err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(<-rcpts, " ")
+	if got != "alice@example.com bob@example.com audit@example.com" {
+		t.Errorf("recipients %q", got)
+	}
+}
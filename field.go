@@ -0,0 +1,139 @@
+package html
+
+import (
+	"html"
+)
+
+// Classes of the parts of a Field, see FieldErrorClass for its error
+const (
+	FieldClass     = "field"
+	FieldHelpClass = "field-help"
+)
+
+// elementID returns the id of e, giving it a unique one if it does not have one
+func elementID(e Element) string {
+	id := e.GetAttr("id")
+	if len(id) == 0 {
+		id = "html_input_" + getUniqueId()
+		e.AddAttr("id", id)
+	}
+	return id
+}
+
+// For points the label at the input e, so clicking the label focuses the input and screen readers read the label for it
+// e is given an id if it does not have one
+func (l *LabelElement) For(e Element) *LabelElement {
+	l.AddAttr("for", elementID(e))
+	return l
+}
+
+// FieldsetElement groups related fields of a form under a legend
+type FieldsetElement struct {
+	Container
+}
+
+// Fieldset creates a fieldset of the elements, with a legend unless it is "". The legend is text, like the label of Field.
+func Fieldset(legend string, elements ...Element) *FieldsetElement {
+	fs := &FieldsetElement{}
+	if len(legend) > 0 {
+		fs.Add(textLegend(legend))
+	}
+	fs.Add(elements...)
+	return fs
+}
+
+func (fs *FieldsetElement) Write(tw *TagWriter) {
+	tw.WriteTag(TagFieldset, fs)
+}
+
+// LegendElement is the caption of a fieldset
+type LegendElement struct {
+	Attributes
+	legend string
+
+	// text is the legend as plain text, if it was created from text, see Fieldset
+	text string
+}
+
+// Legend creates a legend, legend is HTML like the label of Label
+func Legend(legend string) *LegendElement {
+	return &LegendElement{
+		legend: legend,
+	}
+}
+
+// textLegend creates a legend of text, which is escaped when written
+func textLegend(text string) *LegendElement {
+	return &LegendElement{legend: html.EscapeString(text), text: text}
+}
+
+// textLabel creates a label of text, which is escaped when written
+func textLabel(text string) *LabelElement {
+	return &LabelElement{label: html.EscapeString(text), text: text}
+}
+func (l *LegendElement) Write(tw *TagWriter) {
+	tw.WriteTag(TagLegend, l)
+}
+func (l *LegendElement) WriteContent(tw *TagWriter) {
+	tw.WriteString(l.legend)
+}
+
+// FieldElement is a labelled form field: a label, the input, optional help text and the error of the field
+type FieldElement struct {
+	Container
+
+	input Element
+	help  *SpanElement
+}
+
+// Field creates a labelled field for the input, the label is text
+// A RadioGroup or CheckboxGroup is written as a fieldset with the label as its legend, other inputs as a div
+func Field(label string, input Element) *FieldElement {
+	f := &FieldElement{input: input}
+	f.AddClassName(FieldClass)
+	if _, ok := input.(*ChoiceGroupElement); ok {
+		f.Add(textLegend(label))
+	} else {
+		f.Add(textLabel(label).For(input))
+	}
+	f.Add(input)
+	return f
+}
+
+// Help adds help text after the input, which screen readers read with the input
+func (f *FieldElement) Help(text string) *FieldElement {
+	if f.help == nil {
+		f.help = Span()
+		f.help.AddClassName(FieldHelpClass)
+		f.help.AddAttr("id", elementID(f.input)+"-help")
+		f.input.AddAttr("aria-describedby", f.help.GetAttr("id"))
+		f.Add(f.help)
+	}
+	f.help.Add(Text(text))
+	return f
+}
+
+// tag returns the tag the field is written as
+func (f *FieldElement) tag() HtmlTag {
+	if _, ok := f.input.(*ChoiceGroupElement); ok {
+		return TagFieldset
+	}
+	return TagDiv
+}
+
+func (f *FieldElement) Write(tw *TagWriter) {
+	tw.WriteTag(f.tag(), f)
+}
+
+// WriteContent writes the label, input and help, then the error of the field
+func (f *FieldElement) WriteContent(tw *TagWriter) {
+	name := f.input.GetAttr("name")
+	if g, ok := f.input.(*ChoiceGroupElement); ok {
+		name = g.name
+	}
+	held := tw.holdFieldError(name)
+	f.Container.WriteContent(tw)
+	if held {
+		tw.writeNamedFieldError(name)
+	}
+}
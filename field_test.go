@@ -0,0 +1,76 @@
+package html
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLabelFor(t *testing.T) {
+	in := TextInput("name", 20)
+	label := Label("Name").For(in)
+	id := in.GetAttr("id")
+	if len(id) == 0 || label.GetAttr("for") != id {
+		t.Fatalf("label for %q, input id %q", label.GetAttr("for"), id)
+	}
+
+	in = TextInput("city", 20)
+	in.AddAttr("id", "city")
+	if got := renderString(Label("City").For(in).Write); got != `<label for="city">City</label>`+"\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestFieldset(t *testing.T) {
+	fs := Fieldset("Contact", Text("x"))
+	expected := "<fieldset><legend>Contact</legend>\nx\n</fieldset>\n"
+	if got := renderString(fs.Write); got != expected {
+		t.Errorf("got %q\nexpected %q", got, expected)
+	}
+
+	// Fieldset and Field take text, Label and Legend take HTML
+	for _, tc := range []struct {
+		e         Element
+		html      string
+		plainText string
+	}{
+		{Fieldset("Tom & Jerry"), "<legend>Tom &amp; Jerry</legend>", "Tom & Jerry"},
+		{Field("Tom & Jerry", TextInput("cat", 10)), ">Tom &amp; Jerry</label>", "Tom & Jerry"},
+		{Label("<b>Bold</b>"), "<label><b>Bold</b></label>", "<b>Bold</b>"},
+		{Legend("<b>Bold</b>"), "<legend><b>Bold</b></legend>", "<b>Bold</b>"},
+	} {
+		if got := renderString(tc.e.Write); !strings.Contains(got, tc.html) {
+			t.Errorf("got %q\nexpected %q", got, tc.html)
+		}
+		if got := strings.TrimSpace(PlainText(tc.e)); got != tc.plainText {
+			t.Errorf("plain text %q\nexpected %q", got, tc.plainText)
+		}
+	}
+}
+
+func TestField(t *testing.T) {
+	f := Form(NewLink("/contact")).SetName("contact")
+	email := EmailInput("email", 30)
+	email.AddAttr("id", "email")
+	size := RadioGroup("size", Choices("Small", "s", "Large", "l"), "s")
+	f.Add(Field("Email <work>", email).Help("We never share it"), Field("Size", size))
+	f.SetErrors(map[string]string{"email": "Not an email address", "size": "Pick a size"})
+
+	html := renderString(f.Write)
+	for _, expected := range []string{
+		`<div class="field"><label for="email">Email &lt;work&gt;</label>`,
+		`<input aria-describedby="email-help contact-email-error" aria-invalid="true" id="email" name="email" size="30" type="email">`,
+		`<span class="field-help" id="email-help">We never share it` + "\n</span>\n" +
+			`<span class="field-error" id="contact-email-error" role="alert">Not an email address` + "\n</span>\n</div>",
+		`<fieldset class="field"><legend>Size</legend>`,
+		`<span class="field-error" id="contact-size-error" role="alert">Pick a size` + "\n</span>\n</fieldset>",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("missing %s in\n%s", expected, html)
+		}
+	}
+
+	expected := "Email <work> We never share it\nSize\n(x) Small ( ) Large\n"
+	if got := PlainText(f); got != expected {
+		t.Errorf("plain text %q\nexpected %q", got, expected)
+	}
+}
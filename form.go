@@ -1,9 +1,9 @@
 package html
 
 import (
-	"database/sql"
 	"fmt"
 	"strconv"
+	"strings"
 	"sync/atomic"
 )
 
@@ -19,6 +19,15 @@ func getUniqueId() string {
 // Form is the contaner for a form
 type FormElement struct {
 	Container
+
+	// validation is the body of the function which validates the form on submit
+	validation string
+
+	// validators are the rules of each field, see AddValidator
+	validators []fieldValidators
+
+	// errors are written after the fields, see SetErrors
+	errors map[string]string
 }
 
 // List returns a TableElement object`
@@ -62,11 +71,17 @@ func (f *FormElement) MethodPOST() *FormElement {
 	return f
 }
 
+// Multipart makes the form a POST with multipart/form-data encoding, needed to upload files with FileInput
+func (f *FormElement) Multipart() *FormElement {
+	f.AddAttr("enctype", "multipart/form-data")
+	return f.MethodPOST()
+}
+
 func (f *FormElement) ValidateFilled(name string, msg string) *FormElement {
-	docname := "document." + f.FormName() + "." + name
+	docname := "document.forms[" + JSString(f.FormName()) + "].elements[" + JSString(name) + "]"
 
 	script := " if (" + docname + ".value.length < 1) {\n"
-	script += "  alert(\"" + msg + "\");\n"
+	script += "  alert(" + JSString(msg) + ");\n"
 	script += "  " + docname + ".focus();\n"
 	script += "  return false;\n"
 	script += " }\n"
@@ -75,17 +90,50 @@ func (f *FormElement) ValidateFilled(name string, msg string) *FormElement {
 }
 
 func (f *FormElement) AddValidation(script string) *FormElement {
-	f.AddJavaScript(f.validateFunc(), script)
+	f.validation += script
 	return f
 }
 
 // Write writes the HTML form tag and container data
 func (f *FormElement) Write(tw *TagWriter) {
-	f.AddAttr("onsubmit", "return "+f.validateFunc()+"()")
-	f.AddJavaScript(f.validateFunc(), " return true;\n")
+	f.applyConstraints()
 	tw.WriteTag(TagForm, f)
 }
 
+// WriteContent writes the form elements, followed by the validation function and the submit listener which calls it
+// unless the form is in a Document, which writes them in its script
+func (f *FormElement) WriteContent(tw *TagWriter) {
+	if len(f.errors) > 0 {
+		outer := tw.fieldErrors
+		tw.fieldErrors = &fieldErrors{form: f.FormName(), errors: f.errors, written: make(map[string]bool)}
+		defer func() { tw.fieldErrors = outer }()
+	}
+	if len(tw.csrfToken) > 0 && strings.EqualFold(f.GetAttr("method"), "POST") {
+		Hidden(tw.csrfField, tw.csrfToken).Write(tw)
+	}
+	f.Container.WriteContent(tw)
+	if (len(f.validation) == 0 && len(f.validators) == 0) || tw.gathered {
+		return
+	}
+	// the functions of the container were written by Container.WriteContent
+	r := newJSRegistry()
+	f.contributeValidation(r)
+	r.script(ScriptBodyEnd).Write(tw)
+}
+
+func (f *FormElement) contributeJavaScript(r *jsRegistry) {
+	f.Container.contributeJavaScript(r)
+	f.contributeValidation(r)
+}
+
+// contributeValidation adds the validation function of the form and its submit listener
+func (f *FormElement) contributeValidation(r *jsRegistry) {
+	if validation := f.validatorJavaScript() + f.validation; len(validation) > 0 {
+		r.function(f.validateFunc(), validation+" return true;")
+		r.listen("document.forms["+JSString(f.FormName())+"]", "submit", f.validateFunc())
+	}
+}
+
 type InputElement struct {
 	Attributes
 
@@ -133,17 +181,9 @@ func (i *InputElement) SetDefault(value string) *InputElement {
 	return i
 }
 
+// SetDefaultInt sets the default of the input to a number or sql.Null number, see SetDefaultValue
 func (i *InputElement) SetDefaultInt(value interface{}) *InputElement {
-	switch value.(type) {
-	case sql.NullInt64:
-		sv := value.(sql.NullInt64)
-		if sv.Valid {
-			i.SetDefault(strconv.FormatInt(sv.Int64, 10))
-		}
-	default:
-		i.SetDefault(fmt.Sprintf("%d", value))
-	}
-	return i
+	return i.SetDefaultValue(value)
 }
 
 type CheckboxElement struct {
@@ -179,8 +219,12 @@ func (e *CheckboxElement) Write(tw *TagWriter) {
 type LabelElement struct {
 	Attributes
 	label string
+
+	// text is the label as plain text, if it was created from text, see Field
+	text string
 }
 
+// Label creates a label, label is HTML
 func Label(label string) *LabelElement {
 	l := &LabelElement{
 		label: label,
@@ -219,7 +263,18 @@ func (ta *TextAreaElement) WriteContent(tw *TagWriter) {
 
 type FormSelectElement struct {
 	Attributes
+
+	// items are the options and option groups, in order
+	items []Element
+
+	// options are all the options, including those in groups
 	options []*OptionElement
+
+	// values are the current values, see SetValue
+	values []string
+
+	// placeholder is the disabled option shown when nothing is selected
+	placeholder *OptionElement
 }
 
 func FormSelect(name string) *FormSelectElement {
@@ -228,35 +283,43 @@ func FormSelect(name string) *FormSelectElement {
 	return s
 }
 
+// Option adds an option, which is selected if value is a current value of the select
 func (e *FormSelectElement) Option(display, value string) *OptionElement {
+	opt := e.newOption(display, value)
+	e.items = append(e.items, opt)
+	return opt
+}
+
+// newOption creates an option of the select, without adding it to the items
+func (e *FormSelectElement) newOption(display, value string) *OptionElement {
 	opt := &OptionElement{
 		display: display,
 	}
 	opt.AddAttr("value", value)
+	if containsString(e.values, value) {
+		opt.Selected()
+	}
 	e.options = append(e.options, opt)
 	return opt
 }
 
+// OptionInt adds an option with a number or sql.Null number value, see OptionValue
 func (e *FormSelectElement) OptionInt(display string, value interface{}) *OptionElement {
-	var s string
-	switch value.(type) {
-	case sql.NullInt64:
-		sv := value.(sql.NullInt64)
-		if sv.Valid {
-			s = strconv.FormatInt(sv.Int64, 10)
-		}
-	default:
-		s = fmt.Sprintf("%d", value)
-	}
-	return e.Option(display, s)
+	return e.OptionValue(display, value)
 }
 
 func (e *FormSelectElement) Write(tw *TagWriter) {
+	if e.placeholder != nil {
+		e.placeholder.Selected(len(e.Selected()) == 0)
+	}
 	tw.WriteTag(TagSelect, e)
 }
 func (e *FormSelectElement) WriteContent(tw *TagWriter) {
-	for _, opt := range e.options {
-		opt.Write(tw)
+	if e.placeholder != nil {
+		e.placeholder.Write(tw)
+	}
+	for _, item := range e.items {
+		item.Write(tw)
 	}
 }
 
@@ -284,6 +347,9 @@ func (e *OptionElement) WriteContent(tw *TagWriter) {
 type ButtonElement struct {
 	Container
 	buttonText string
+
+	// onclick are the functions called when the button is clicked
+	onclick []string
 }
 
 func Button(buttonText string) *ButtonElement {
@@ -293,14 +359,31 @@ func Button(buttonText string) *ButtonElement {
 }
 
 // OnClick will add an onclick javascipt
+// The script is run by a listener added after the button, the button is given an id if it does not have one
 func (e *ButtonElement) OnClick(js string) {
 	onclick := "onclick_" + getUniqueId()
 	e.AddJavaScript(onclick, js)
-	e.AddAttr("onclick", onclick+"();")
+	if len(e.GetAttr("id")) == 0 {
+		e.AddAttr("id", "html_button_"+getUniqueId())
+	}
+	e.onclick = append(e.onclick, onclick)
 }
 
 func (e *ButtonElement) Write(tw *TagWriter) {
 	tw.WriteTag(TagButton, e)
+	if len(e.onclick) == 0 || tw.gathered {
+		return
+	}
+	r := newJSRegistry()
+	e.contributeJavaScript(r)
+	r.script(ScriptBodyEnd).Write(tw)
+}
+
+func (e *ButtonElement) contributeJavaScript(r *jsRegistry) {
+	e.Container.contributeJavaScript(r)
+	for _, fn := range e.onclick {
+		r.listen("document.getElementById("+JSString(e.GetAttr("id"))+")", "click", fn)
+	}
 }
 
 func (e *ButtonElement) WriteContent(tw *TagWriter) {
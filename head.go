@@ -1,6 +1,10 @@
 package html
 
-import "strconv"
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"strconv"
+)
 
 // Head defines the HTML head element
 type HeadElement struct {
@@ -8,6 +12,12 @@ type HeadElement struct {
 	title  string
 	css    *CSSElement
 	styles *StyleElement
+
+	// linked are the names of styles served by a linked stylesheet, which are not written in the head
+	linked map[string]bool
+
+	// scripts are written at the end of the head, see Document.AddScript
+	scripts []*ScriptElement
 }
 
 func Head() *HeadElement {
@@ -25,12 +35,30 @@ func (head *HeadElement) Write(tw *TagWriter) {
 	tw.WriteTag(TagHead, head)
 }
 
+// WriteContent writes the head elements followed by the scripts of the document placed in the head
+func (head *HeadElement) WriteContent(tw *TagWriter) {
+	head.Container.WriteContent(tw)
+	writeScripts(tw, head.scripts)
+}
+
 // AddTitle Adds a title to the Header
 func (head *HeadElement) AddTitle(title string) {
 	head.title = title
 	head.Add(NewTitle(title))
 }
 
+// AddStylesheet links an external stylesheet into the head
+// Stylesheets are placed ahead of the inline CSS and Styles, so that inline rules can override them
+func (head *HeadElement) AddStylesheet(link *LinkElement) {
+	for i, e := range head.elements {
+		if e == head.css {
+			head.elements = append(head.elements[:i], append([]Element{link}, head.elements[i:]...)...)
+			return
+		}
+	}
+	head.Add(link)
+}
+
 // GetTitle will return the last title set
 func (head *HeadElement) GetTitle() string {
 	return head.title
@@ -81,3 +109,48 @@ func (m *MetaElement) Write(tw *TagWriter) {
 
 func (m *MetaElement) WriteContent(tw *TagWriter) {
 }
+
+// LinkElement is a head link to an external resource
+type LinkElement struct {
+	Attributes
+}
+
+// StylesheetLink creates a link to an external stylesheet, <link rel="stylesheet" href="...">
+func StylesheetLink(href string) *LinkElement {
+	l := &LinkElement{}
+	l.AddAttr("rel", "stylesheet")
+	l.AddAttr("href", href)
+	return l
+}
+
+// Media restricts the stylesheet to a media query, such as MediaPrint
+func (l *LinkElement) Media(query string) *LinkElement {
+	l.AddAttr("media", query)
+	return l
+}
+
+// Integrity sets the subresource integrity hash the browser checks the stylesheet against, see SRIHash
+// Integrity checks of resources from another origin also need CrossOrigin
+func (l *LinkElement) Integrity(hash string) *LinkElement {
+	l.AddAttr("integrity", hash)
+	return l
+}
+
+// CrossOrigin sets the CORS mode used to fetch the resource, "anonymous" or "use-credentials"
+func (l *LinkElement) CrossOrigin(mode string) *LinkElement {
+	l.AddAttr("crossorigin", mode)
+	return l
+}
+
+func (l *LinkElement) Write(tw *TagWriter) {
+	tw.WriteTag(TagLink, l)
+}
+
+func (l *LinkElement) WriteContent(tw *TagWriter) {
+}
+
+// SRIHash returns the sha384 subresource integrity hash of data, for LinkElement.Integrity
+func SRIHash(data []byte) string {
+	sum := sha512.Sum384(data)
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,214 @@
+package html
+
+import (
+	"sort"
+	"strings"
+)
+
+// cssMatch is a declaration that applies to an element, with what is needed to order it in the cascade
+type cssMatch struct {
+	CSSDeclaration
+	inline      bool
+	specificity [3]int
+	order       int
+}
+
+// InlineCSS resolves the rules added with AddStyle and AddCSS against the body of the document
+// and merges them into the style attribute of each element, as most email clients ignore style blocks.
+// Declarations are applied in cascade order: !important, then existing inline styles, then specificity,
+// then the order the rules were added.
+// If remove is set the head style blocks are removed, rules which can not be inlined, such as
+// :hover or @media, are kept in a single head CSS block.
+func (doc *Document) InlineCSS(remove bool) error {
+	rules, err := doc.cssRules()
+	if err != nil {
+		return err
+	}
+
+	tree := newStyleTree(doc)
+	matches := make(map[*styleNode][]cssMatch)
+	var keep []string
+
+	for order, rule := range rules {
+		if len(rule.AtKeyword) > 0 {
+			keep = append(keep, rule.String())
+			continue
+		}
+		sels, err := parseSelectorList(rule.Selectors)
+		if err != nil {
+			return err
+		}
+		var dynamic []string
+		for _, sel := range sels {
+			if !sel.inlinable() {
+				dynamic = append(dynamic, sel.text)
+				continue
+			}
+			spec := sel.specificity()
+			tree.walk(func(n *styleNode) {
+				if n.tag == "head" || (n.parent != nil && n.parent.tag == "head") || !sel.match(n) {
+					return
+				}
+				for _, d := range rule.Declarations {
+					matches[n] = append(matches[n], cssMatch{CSSDeclaration: d, specificity: spec, order: order})
+				}
+			})
+		}
+		if len(dynamic) > 0 {
+			keep = append(keep, (&CSSRule{Selectors: strings.Join(dynamic, ", "), Declarations: rule.Declarations}).String())
+		}
+	}
+
+	tree.walk(func(n *styleNode) {
+		if m, ok := matches[n]; ok {
+			n.e.AddAttr("style", cascade(m, n.e.GetAttr("style")))
+		}
+	})
+
+	if remove {
+		doc.head.css.css = nil
+		doc.head.replaceStyles(NewStyles())
+		if len(keep) > 0 {
+			doc.head.css.Add(CSS(strings.Join(keep, "\n")))
+		}
+	}
+	return nil
+}
+
+// cssRules returns the rules of the document in the order they are rendered, CSS first, then Styles and StyleGroups
+func (doc *Document) cssRules() ([]*CSSRule, error) {
+	doc.addScopedStyles()
+	var rules []*CSSRule
+	for _, css := range doc.head.css.css {
+		if css.err != nil {
+			return nil, css.err
+		}
+		rules = append(rules, css.sheet.Rules...)
+	}
+	for _, r := range doc.head.styles.rules {
+		if group, ok := r.(*StyleGroup); ok {
+			sheet, err := ParseCSS(group.String())
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, sheet.Rules...)
+			continue
+		}
+		style := r.(*Style)
+		if len(style.associations) == 0 || len(style.keys) == 0 {
+			continue
+		}
+		rule := &CSSRule{Selectors: strings.Join(style.associations, ", ")}
+		for _, k := range style.keys {
+			rule.Declarations = append(rule.Declarations, parseDeclaration(k, style.styles[k]))
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// replaceStyles swaps the StyleElement in the head for styles, keeping its place
+func (head *HeadElement) replaceStyles(styles *StyleElement) {
+	for i, e := range head.elements {
+		if e == head.styles {
+			head.elements[i] = styles
+		}
+	}
+	head.styles = styles
+}
+
+// cascade merges the matched declarations with the existing style attribute and returns the new attribute
+func cascade(matches []cssMatch, style string) string {
+	for _, d := range parseDeclarations(style) {
+		matches = append(matches, cssMatch{CSSDeclaration: d, inline: true})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Important != b.Important {
+			return b.Important
+		}
+		if a.inline != b.inline {
+			return b.inline
+		}
+		if c := compareSpecificity(a.specificity, b.specificity); c != 0 {
+			return c < 0
+		}
+		return a.order < b.order
+	})
+
+	// the last declaration of each property wins, properties keep the place they first appeared
+	var keys []string
+	values := make(map[string]string)
+	for _, m := range matches {
+		if _, ok := values[m.Property]; !ok {
+			keys = append(keys, m.Property)
+		}
+		values[m.Property] = m.Value
+	}
+	s := make([]string, len(keys))
+	for i, k := range keys {
+		s[i] = k + ":" + values[k]
+	}
+	return strings.Join(s, ";")
+}
+
+// parseDeclarations parses a list of declarations, "color: red; margin: 0"
+func parseDeclarations(s string) []CSSDeclaration {
+	var decls []CSSDeclaration
+	for len(s) > 0 {
+		end := indexOutside(s, ';')
+		if end < 0 {
+			end = len(s)
+		}
+		if colon := strings.IndexByte(s[:end], ':'); colon > 0 {
+			decls = append(decls, parseDeclaration(s[:colon], s[colon+1:end]))
+		}
+		if end == len(s) {
+			break
+		}
+		s = s[end+1:]
+	}
+	return decls
+}
+
+// parseDeclaration separates !important from the value
+// Custom properties, --name, are case sensitive and keep their case
+func parseDeclaration(property string, value string) CSSDeclaration {
+	d := CSSDeclaration{
+		Property: strings.TrimSpace(property),
+		Value:    strings.TrimSpace(value),
+	}
+	if !strings.HasPrefix(d.Property, "--") {
+		d.Property = strings.ToLower(d.Property)
+	}
+	if i := strings.LastIndexByte(d.Value, '!'); i >= 0 && strings.EqualFold(strings.TrimSpace(d.Value[i+1:]), "important") {
+		d.Important = true
+		d.Value = strings.TrimSpace(d.Value[:i])
+	}
+	return d
+}
+
+// indexOutside returns the index of the first c which is not inside quotes or parentheses
+func indexOutside(s string, c byte) int {
+	var quote byte
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == '\\':
+			i++
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case ch == c && depth == 0:
+			return i
+		}
+	}
+	return -1
+}
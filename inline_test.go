@@ -0,0 +1,76 @@
+package html
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestInlineCSS(t *testing.T) {
+	doc := NewDocument()
+	doc.AddCSS(CSS(`
+		/* base */
+		p { color: red; margin: 0 }
+		#main p { color: green }
+		td:first-child, a:hover { font-weight: bold }
+		.note { color: blue !important }
+		@media print { p { display: none } }
+	`))
+
+	note := NewClass("note")
+	style := NewStyle("late", StyleDef{Key: "color", Value: "black"}, StyleDef{Key: "padding", Value: "1px"})
+	note.AddStyle(style)
+	doc.AddStyle(style)
+
+	plain := P(Text("plain"))
+	inMain := P(Text("main"))
+	noted := P(Text("noted"))
+	noted.AddClass(note)
+	styled := P(Text("styled"))
+	styled.Style("color", "orange")
+
+	main := Div(inMain)
+	main.AddAttr("id", "main")
+
+	tbl := Table()
+	row := tbl.Row()
+	first := row.CellString("a")
+	second := row.CellString("b")
+
+	doc.Body().Add(plain, main, noted, styled, tbl)
+
+	if err := doc.InlineCSS(true); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		e        Element
+		expected string
+	}{
+		{"plain", plain, "color:red;margin:0"},
+		{"specificity", inMain, "color:green;margin:0"},
+		{"important", noted, "color:blue;margin:0;padding:1px"},
+		{"inline", styled, "color:orange;margin:0"},
+		{"structural", first, "font-weight:bold"},
+		{"unmatched", second, ""},
+	} {
+		if got := tc.e.GetAttr("style"); got != tc.expected {
+			t.Errorf("%s: style %q, expected %q", tc.name, got, tc.expected)
+		}
+	}
+
+	var b bytes.Buffer
+	doc.IoRender(&b)
+	html := b.String()
+	for _, s := range []string{"a:hover", "@media print"} {
+		if !strings.Contains(html, s) {
+			t.Errorf("rule %q was not kept in the head", s)
+		}
+	}
+	for _, s := range []string{"#main p", "Style late"} {
+		if strings.Contains(html, s) {
+			t.Errorf("inlined rule %q was not removed from the head", s)
+		}
+	}
+}
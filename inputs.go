@@ -0,0 +1,220 @@
+package html
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTML input value layouts for months, see WeekInput for weeks
+const monthLayout = "2006-01"
+
+// input creates an input of type typ called name
+func input(typ string, name string) *InputElement {
+	i := &InputElement{Name: name}
+	i.AddAttr("type", typ)
+	i.AddAttr("name", name)
+	return i
+}
+
+// textLikeInput creates an input of type typ with a size, for types which take text
+func textLikeInput(typ string, name string, size int) *InputElement {
+	i := input(typ, name)
+	i.AddAttr("size", strconv.Itoa(size))
+	return i
+}
+
+// PasswordInput creates a password input, the value is never shown
+func PasswordInput(name string, size int) *InputElement {
+	return textLikeInput("password", name, size)
+}
+
+// EmailInput creates an email address input
+func EmailInput(name string, size int) *InputElement {
+	return textLikeInput("email", name, size)
+}
+
+// TelInput creates a telephone number input
+func TelInput(name string, size int) *InputElement {
+	return textLikeInput("tel", name, size)
+}
+
+// URLInput creates a URL input
+func URLInput(name string, size int) *InputElement {
+	return textLikeInput("url", name, size)
+}
+
+// SearchInput creates a search input
+func SearchInput(name string, size int) *InputElement {
+	return textLikeInput("search", name, size)
+}
+
+// NumberInput creates a number input, use Min, Max and Step to limit it
+func NumberInput(name string) *InputElement {
+	return input("number", name)
+}
+
+// RangeInput creates a slider from min to max in steps of step
+func RangeInput(name string, min float64, max float64, step float64) *InputElement {
+	return input("range", name).Min(min).Max(max).Step(step)
+}
+
+// DateInput creates a date input, use SetTime for the default
+func DateInput(name string) *InputElement {
+	return input("date", name)
+}
+
+// TimeInput creates a time of day input
+func TimeInput(name string) *InputElement {
+	return input("time", name)
+}
+
+// DateTimeInput creates a date and time input, in local time, datetime-local
+func DateTimeInput(name string) *InputElement {
+	return input("datetime-local", name)
+}
+
+// MonthInput creates a year and month input
+func MonthInput(name string) *InputElement {
+	return input("month", name)
+}
+
+// WeekInput creates a year and ISO week input
+func WeekInput(name string) *InputElement {
+	return input("week", name)
+}
+
+// ColorInput creates a color picker, with a default color
+func ColorInput(name string, color Color) *InputElement {
+	return input("color", name).SetColor(color)
+}
+
+// FileInput creates a file upload, limited to files with the extensions, such as ".csv", and their Mimes types
+// The form must use MethodPOST and multipart/form-data encoding, see FormElement.Multipart
+func FileInput(name string, extensions ...string) *InputElement {
+	i := input("file", name)
+	var accept []string
+	for _, ext := range extensions {
+		ext = strings.ToLower(ext)
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		accept = append(accept, ext)
+		if mt, ok := Mimes[ext]; ok {
+			for _, m := range strings.Fields(mt.Mime) {
+				if !containsString(accept, m) {
+					accept = append(accept, m)
+				}
+			}
+		}
+	}
+	if len(accept) > 0 {
+		i.AddAttr("accept", strings.Join(accept, ","))
+	}
+	return i
+}
+
+// ResetButton creates a button which resets the form to its defaults
+func ResetButton(label string) *InputElement {
+	i := &InputElement{}
+	i.AddAttr("type", "reset")
+	i.AddAttr("value", label)
+	return i
+}
+
+// ImageButton creates a submit button shown as an image, alt is the text for screen readers
+func ImageButton(name string, src string, alt string) *InputElement {
+	i := input("image", name)
+	i.AddAttr("src", src)
+	i.AddAttr("alt", alt)
+	return i
+}
+
+// Multiple allows more than one file or email address
+func (i *InputElement) Multiple() *InputElement {
+	i.AddAttr("multiple", "true")
+	return i
+}
+
+// Min sets the lowest value of a number or range
+func (i *InputElement) Min(min float64) *InputElement {
+	i.AddAttr("min", formatFloat(min))
+	return i
+}
+
+// Max sets the highest value of a number or range
+func (i *InputElement) Max(max float64) *InputElement {
+	i.AddAttr("max", formatFloat(max))
+	return i
+}
+
+// Step sets the granularity of a number or range, 0 allows any value
+func (i *InputElement) Step(step float64) *InputElement {
+	if step == 0 {
+		i.AddAttr("step", "any")
+	} else {
+		i.AddAttr("step", formatFloat(step))
+	}
+	return i
+}
+
+// SetNumber sets the default of a number or range
+func (i *InputElement) SetNumber(value float64) *InputElement {
+	return i.SetDefault(formatFloat(value))
+}
+
+// SetTime sets the default of a date, time, datetime-local, month or week input, a zero time clears it
+func (i *InputElement) SetTime(t time.Time) *InputElement {
+	if t.IsZero() {
+		delete(i.attrs, "value")
+		return i
+	}
+	return i.SetDefault(i.formatTime(t))
+}
+
+// MinTime sets the earliest date or time of a date, time, datetime-local, month or week input
+func (i *InputElement) MinTime(t time.Time) *InputElement {
+	i.AddAttr("min", i.formatTime(t))
+	return i
+}
+
+// MaxTime sets the latest date or time of a date, time, datetime-local, month or week input
+func (i *InputElement) MaxTime(t time.Time) *InputElement {
+	i.AddAttr("max", i.formatTime(t))
+	return i
+}
+
+// formatTime formats t as the value of the input type
+func (i *InputElement) formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	switch i.GetAttr("type") {
+	case "time":
+		return t.Format(timeLayout)
+	case "datetime-local":
+		return t.Format(datetimeLayout)
+	case "month":
+		return t.Format(monthLayout)
+	case "week":
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	}
+	return t.Format(dateLayout)
+}
+
+// SetColor sets the default of a color input
+func (i *InputElement) SetColor(color Color) *InputElement {
+	return i.SetDefault(color.Hex())
+}
+
+// Placeholder sets the hint shown in an empty input
+func (i *InputElement) Placeholder(text string) *InputElement {
+	i.AddAttr("placeholder", text)
+	return i
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,49 @@
+package html
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInputTypes(t *testing.T) {
+	day := time.Date(2024, 12, 30, 14, 5, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		e        Element
+		expected string
+	}{
+		{PasswordInput("pw", 12), `<input name="pw" size="12" type="password">`},
+		{EmailInput("to", 30).Multiple(), `<input multiple name="to" size="30" type="email">`},
+		{NumberInput("qty").Min(1).Max(10).Step(0.5).SetNumber(2), `<input max="10" min="1" name="qty" step="0.5" type="number" value="2">`},
+		{RangeInput("vol", 0, 11, 0), `<input max="11" min="0" name="vol" step="any" type="range">`},
+		{DateInput("d").SetTime(day).MinTime(day), `<input min="2024-12-30" name="d" type="date" value="2024-12-30">`},
+		{TimeInput("t").SetTime(day), `<input name="t" type="time" value="14:05">`},
+		{DateTimeInput("dt").SetTime(day), `<input name="dt" type="datetime-local" value="2024-12-30T14:05">`},
+		{MonthInput("m").SetTime(day), `<input name="m" type="month" value="2024-12">`},
+		{WeekInput("w").SetTime(day), `<input name="w" type="week" value="2025-W01">`},
+		{DateInput("empty").SetTime(time.Time{}), `<input name="empty" type="date">`},
+		{ColorInput("c", MustColor("rebeccapurple")), `<input name="c" type="color" value="#663399">`},
+		{TelInput("tel", 15).Placeholder("+47"), `<input name="tel" placeholder="+47" size="15" type="tel">`},
+		{URLInput("u", 40), `<input name="u" size="40" type="url">`},
+		{SearchInput("q", 20), `<input name="q" size="20" type="search">`},
+		{FileInput("f", ".csv", "PDF", ".xyz"), `<input accept=".csv,text/csv,.pdf,application/pdf,.xyz" name="f" type="file">`},
+		{ResetButton("Clear"), `<input type="reset" value="Clear">`},
+		{ImageButton("go", "/go.png", "Go"), `<input alt="Go" name="go" src="/go.png" type="image">`},
+	} {
+		if got := strings.TrimSpace(renderString(tc.e.Write)); got != tc.expected {
+			t.Errorf("got      %s\nexpected %s", got, tc.expected)
+		}
+	}
+
+	var v struct {
+		Week  time.Time
+		Month time.Time
+	}
+	if err := DecodeForm(url.Values{"Week": {"2025-W01"}, "Month": {"2024-12"}}, &v); err != nil {
+		t.Fatal(err)
+	}
+	if !v.Week.Equal(time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC)) || !v.Month.Equal(time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("decoded %+v", v)
+	}
+}
@@ -0,0 +1,139 @@
+package html
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// jsRegistry collects the JavaScript of the elements of a Document, so it is written once in a single script
+type jsRegistry struct {
+	// names are the function names in the order they were first added
+	names []string
+	funcs map[string]string
+
+	// listeners are the statements which add event listeners, in the order they were added
+	listeners []string
+	seen      map[string]bool
+
+	errs []error
+}
+
+func newJSRegistry() *jsRegistry {
+	return &jsRegistry{
+		funcs: make(map[string]string),
+		seen:  make(map[string]bool),
+	}
+}
+
+// scriptContributor is an element with JavaScript to add to the document script
+type scriptContributor interface {
+	contributeJavaScript(r *jsRegistry)
+}
+
+// function adds a function, a function added again with the same code is dropped,
+// one added with different code is an error and the first definition is kept
+func (r *jsRegistry) function(name string, body string) {
+	if old, ok := r.funcs[name]; ok {
+		if old != body {
+			r.errs = append(r.errs, fmt.Errorf("javascript: function %s is defined more than once with different code", name))
+		}
+		return
+	}
+	r.names = append(r.names, name)
+	r.funcs[name] = body
+}
+
+// listen adds a listener which calls fn when event fires on target
+func (r *jsRegistry) listen(target string, event string, fn string) {
+	js := listenerJS(target, event, fn)
+	if !r.seen[js] {
+		r.seen[js] = true
+		r.listeners = append(r.listeners, js)
+	}
+}
+
+// script returns the collected JavaScript as one script, or nil if there is none
+// In the head the listeners wait for the page to load, as the elements do not exist yet
+// Conflicting functions are reported on the browser console, as only the first definition is written
+func (r *jsRegistry) script(place ScriptPlace) *ScriptElement {
+	if len(r.names) == 0 && len(r.listeners) == 0 {
+		return nil
+	}
+	sb := strings.Builder{}
+	for _, err := range r.errs {
+		sb.WriteString("console.error(" + JSString(err.Error()) + ");\n")
+	}
+	for _, name := range r.names {
+		sb.WriteString(jsFunction(name, r.funcs[name]))
+	}
+	if len(r.listeners) > 0 {
+		listeners := strings.Join(r.listeners, "")
+		if place == ScriptHead {
+			listeners = "document.addEventListener(\"DOMContentLoaded\", function() {\n" + listeners + "});\n"
+		}
+		sb.WriteString(listeners)
+	}
+	return Script(sb.String())
+}
+
+// err returns the conflicts found collecting the JavaScript
+func (r *jsRegistry) err() error {
+	if len(r.errs) == 0 {
+		return nil
+	}
+	s := make([]string, len(r.errs))
+	for i, err := range r.errs {
+		s[i] = err.Error()
+	}
+	return fmt.Errorf("%s", strings.Join(s, "\n"))
+}
+
+// jsFunction returns the definition of a function without arguments
+func jsFunction(name string, body string) string {
+	return fmt.Sprintf("function %s() {\n%s\n}\n", name, body)
+}
+
+// listenerJS returns a statement which calls fn when event fires on the element target, a JavaScript expression,
+// instead of an inline event attribute which a Content-Security-Policy would block
+// The default action is cancelled if fn returns false
+func listenerJS(target string, event string, fn string) string {
+	return fmt.Sprintf("%s.addEventListener(%s, function(event) {\n if (%s() === false) {\n  event.preventDefault();\n }\n});\n",
+		target, JSString(event), fn)
+}
+
+// contributeJavaScript adds the functions of the container, sorted by name
+func (c *Container) contributeJavaScript(r *jsRegistry) {
+	names := make([]string, 0, len(c.javaScript))
+	for k := range c.javaScript {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		r.function(k, c.javaScript[k])
+	}
+}
+
+// SetScriptPlace sets where the JavaScript of the elements of the document is written, ScriptBodyEnd by default
+func (doc *Document) SetScriptPlace(place ScriptPlace) {
+	doc.scriptPlace = place
+}
+
+// gatherJavaScript collects the JavaScript of the elements of the document
+func (doc *Document) gatherJavaScript() *jsRegistry {
+	r := newJSRegistry()
+	Walk(doc, func(e Element) bool {
+		if c, ok := e.(scriptContributor); ok {
+			c.contributeJavaScript(r)
+		}
+		return true
+	})
+	return r
+}
+
+// CheckJavaScript returns an error listing the functions added with AddJavaScript by different elements
+// under the same name but with different code. Only the first definition of such a function is written,
+// and the error is logged to the browser console when the document is rendered.
+func (doc *Document) CheckJavaScript() error {
+	return doc.gatherJavaScript().err()
+}
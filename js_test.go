@@ -0,0 +1,75 @@
+package html
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocumentJavaScript(t *testing.T) {
+	doc := NewDocument()
+	tbl := Table()
+	for i := 0; i < 2; i++ {
+		form := Form(NewLink("/row")).ValidateFilled("qty", "Quantity is required")
+		form.AddJavaScript("formatQty", "return 1;")
+		tbl.Row().Cell(form)
+	}
+	doc.Body().Add(tbl, Text("end"))
+
+	html := renderString(doc.Write)
+	table := html[strings.Index(html, "<table"):strings.Index(html, "</table>")]
+	if strings.Contains(table, "<script") {
+		t.Errorf("script inside the table:\n%s", table)
+	}
+	if n := strings.Count(html, "<script"); n != 1 {
+		t.Errorf("%d scripts, expected 1:\n%s", n, html)
+	}
+	if n := strings.Count(html, "function formatQty()"); n != 1 {
+		t.Errorf("formatQty defined %d times", n)
+	}
+	if n := strings.Count(html, `addEventListener("submit"`); n != 2 {
+		t.Errorf("%d submit listeners, expected 2", n)
+	}
+	if strings.Index(html, "<script") < strings.Index(html, "end") {
+		t.Errorf("script is not at the end of the body:\n%s", html)
+	}
+	if err := doc.CheckJavaScript(); err != nil {
+		t.Error(err)
+	}
+
+	other := Div()
+	other.AddJavaScript("formatQty", "return 2;")
+	doc.Body().Add(other)
+	if err := doc.CheckJavaScript(); err == nil || !strings.Contains(err.Error(), "formatQty") {
+		t.Errorf("expected duplicate function error, got %v", err)
+	}
+	if html := renderString(doc.Write); !strings.Contains(html, `console.error("javascript: function formatQty is defined more than once with different code");`) {
+		t.Errorf("conflict not reported in the script:\n%s", html)
+	}
+
+	doc.SetScriptPlace(ScriptHead)
+	html = renderString(doc.Write)
+	head := html[:strings.Index(html, "</head>")]
+	if !strings.Contains(head, "function formatQty()") || !strings.Contains(head, `document.addEventListener("DOMContentLoaded"`) {
+		t.Errorf("head script:\n%s", head)
+	}
+
+	// outside of a document elements still write their own scripts
+	if s := renderString(other.Write); !strings.Contains(s, "function formatQty()") {
+		t.Errorf("standalone div:\n%s", s)
+	}
+}
+
+func TestFormJavaScriptOutsideDocument(t *testing.T) {
+	f := Form(NewLink("/")).SetName("f")
+	f.AddJavaScript("helper", "return 1;")
+	f.ValidateFilled("name", "Name is required")
+	f.Add(TextInput("name", 10))
+
+	html := renderString(f.Write)
+	if n := strings.Count(html, "function helper("); n != 1 {
+		t.Errorf("helper written %d times in\n%s", n, html)
+	}
+	if n := strings.Count(html, "function webForm_f_Validate("); n != 1 {
+		t.Errorf("validation written %d times in\n%s", n, html)
+	}
+}
@@ -0,0 +1,108 @@
+package html
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// go:nofmt
+	MediaAll          = "all"
+	MediaPrint        = "print"
+	MediaScreen       = "screen"
+	MediaDark         = "(prefers-color-scheme: dark)"
+	MediaLight        = "(prefers-color-scheme: light)"
+	MediaReduceMotion = "(prefers-reduced-motion: reduce)"
+	// go:fmt
+)
+
+// MediaMaxWidth is a media query for screens up to px pixels wide
+func MediaMaxWidth(px int) string {
+	return "(max-width: " + strconv.Itoa(px) + "px)"
+}
+
+// MediaMinWidth is a media query for screens at least px pixels wide
+func MediaMinWidth(px int) string {
+	return "(min-width: " + strconv.Itoa(px) + "px)"
+}
+
+// MediaAnd combines media queries that must all match, MediaAnd(MediaScreen, MediaMaxWidth(600))
+func MediaAnd(queries ...string) string {
+	return strings.Join(queries, " and ")
+}
+
+// StyleGroup wraps styles, and other groups, in a conditional group rule such as @media or @supports
+// Styles inside the group are written in the order they are added
+type StyleGroup struct {
+	// rule is the group prelude, such as "@media print"
+	rule  string
+	rules []styleRule
+	err   error
+}
+
+// Media creates a group of styles which only apply when the media query matches
+func Media(query string, styles ...*Style) *StyleGroup {
+	return newStyleGroup("@media", query, styles)
+}
+
+// Supports creates a group of styles which only apply when the browser supports the condition, "(display: grid)"
+func Supports(condition string, styles ...*Style) *StyleGroup {
+	return newStyleGroup("@supports", condition, styles)
+}
+
+func newStyleGroup(at string, condition string, styles []*Style) *StyleGroup {
+	g := &StyleGroup{
+		rule: at + " " + strings.TrimSpace(condition),
+	}
+	switch {
+	case len(strings.TrimSpace(condition)) == 0:
+		g.err = fmt.Errorf("%s: missing condition", at)
+	case strings.ContainsAny(condition, "{};<"):
+		g.err = fmt.Errorf("%s: invalid condition %q", at, condition)
+	}
+	g.Add(styles...)
+	return g
+}
+
+// Add adds styles to the group
+func (g *StyleGroup) Add(styles ...*Style) *StyleGroup {
+	for _, style := range styles {
+		g.rules = append(g.rules, style)
+	}
+	return g
+}
+
+// AddGroup nests groups inside this group, after any styles already added
+func (g *StyleGroup) AddGroup(groups ...*StyleGroup) *StyleGroup {
+	for _, group := range groups {
+		if group.err != nil && g.err == nil {
+			g.err = group.err
+		}
+		g.rules = append(g.rules, group)
+	}
+	return g
+}
+
+// Err returns an error if the group, or a nested group, has an invalid condition
+func (g *StyleGroup) Err() error {
+	return g.err
+}
+
+// Write renders the group to the io.Writer
+func (g *StyleGroup) Write(tw *TagWriter) {
+	if len(g.rules) == 0 {
+		return
+	}
+	tw.WriteString(g.rule)
+	tw.Write(styleOpen)
+	for _, rule := range g.rules {
+		rule.Write(tw)
+	}
+	tw.Write(styleClose)
+}
+
+// String returns the group as CSS
+func (g *StyleGroup) String() string {
+	return renderString(g.Write)
+}
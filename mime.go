@@ -1,5 +1,10 @@
 package html
 
+import (
+	"path/filepath"
+	"strings"
+)
+
 type MimeType struct {
 	Description string
 	Mime        string
@@ -33,7 +38,7 @@ var (
 		".jar":    {Description: "Java Archive (JAR)",                             Mime: "application/java-archive"},
 		".jpeg":   {Description: "JPEG images",                                    Mime: "image/jpeg"},
 		".jpg":    {Description: "JPEG images",                                    Mime: "image/jpeg"},
-		".js":     {Description: "JavaScript",                                     Mime: "text/javascript, per the following specifications:"},
+		".js":     {Description: "JavaScript",                                     Mime: "text/javascript"},
 		".json":   {Description: "JSON format",                                    Mime: "application/json"},
 		".jsonld": {Description: "JSON-LD format",                                 Mime: "application/ld+json"},
 		".midi":   {Description: "Musical Instrument Digital Interface (MIDI)",    Mime: "audio/midi audio/x-midi"},
@@ -85,3 +90,14 @@ var (
 	}
 	// go:fmt
 )
+
+// MimeTypeOf returns the mime type for a file name based on its extension
+// application/octet-stream is returned if the extension is not in Mimes
+func MimeTypeOf(filename string) string {
+	mt, ok := Mimes[strings.ToLower(filepath.Ext(filename))]
+	if !ok {
+		return "application/octet-stream"
+	}
+	// some types list alternatives, the first is the preferred type
+	return strings.Fields(mt.Mime)[0]
+}
@@ -0,0 +1,438 @@
+package html
+
+import (
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	textBullet      = "* "
+	textColumnSep   = "  "
+	textDescIndent  = "    "
+	textHeadingRule = []rune{'=', '-'}
+)
+
+// TextWriter renders an element tree as plain text rather than HTML
+// It is used to build the text/plain alternative of a Document, or for command line output
+type TextWriter struct {
+	sb strings.Builder
+
+	// nls is the number of newlines at the end of the output, -1 if nothing has been written
+	nls int
+
+	// space is set when whitespace was seen and a single space is pending
+	space bool
+
+	// preformatted keeps whitespace and line breaks as they are (pre)
+	preformatted bool
+
+	// indent is written at the start of each line
+	indent string
+
+	// first, when set, replaces the indent on the next line (list bullets)
+	first string
+}
+
+// NewTextWriter creates an empty TextWriter
+func NewTextWriter() *TextWriter {
+	return &TextWriter{nls: -1}
+}
+
+// PlainText renders an element, and all of its children, as plain text
+func PlainText(e Element) string {
+	tw := NewTextWriter()
+	tw.Element(e)
+	return tw.String()
+}
+
+// TextRender will write the Document as plain text to the supplied io.Writer
+func (doc *Document) TextRender(w io.Writer) {
+	io.WriteString(w, PlainText(doc))
+}
+
+// String returns the text rendered so far, with trailing blank lines removed
+func (tw *TextWriter) String() string {
+	s := strings.TrimRight(tw.sb.String(), "\n")
+	if len(s) == 0 {
+		return ""
+	}
+	return s + "\n"
+}
+
+// Element renders e into the TextWriter
+func (tw *TextWriter) Element(e Element) {
+	switch t := e.(type) {
+	case nil:
+	case *Document:
+		tw.Element(t.body)
+	case *HeadElement, *ScriptElement, *StyleElement, *CSSElement, *htmlComment, *MetaElement,
+		*TextAreaElement, *FormSelectElement:
+		// not visible as text
+	case *TextElement:
+		tw.Text(t.text)
+	case *NonBreakingSpace:
+		tw.inline(strings.Repeat(" ", t.count))
+	case *BreakElement:
+		for i := 0; i < t.count; i++ {
+			tw.Newline()
+		}
+	case *PreElement:
+		tw.block(2)
+		tw.preformatted = true
+		tw.Elements(t.elements...)
+		tw.preformatted = false
+		tw.block(2)
+	case *IOReaderElement:
+		tw.block(1)
+		tw.ioReader(t)
+		tw.block(1)
+	case *ParagraphElement:
+		tw.block(2)
+		tw.Elements(t.elements...)
+		tw.block(2)
+	case *DivElement:
+		tw.block(1)
+		tw.Elements(t.elements...)
+		tw.block(1)
+	case *FieldsetElement, *FieldElement, *ChoiceGroupElement:
+		tw.block(1)
+		for _, e := range children(t) {
+			tw.Element(e)
+			tw.space = true
+		}
+		tw.block(1)
+	case *HeadingElement:
+		tw.heading(t)
+	case *ListElement:
+		tw.list(t)
+	case *TableElement:
+		tw.table(t)
+	case *URL:
+		tw.url(t)
+	case *ImageElement:
+		if alt := t.GetAttr("alt"); len(alt) > 0 {
+			tw.Text("[" + alt + "]")
+		}
+	case *ButtonElement:
+		tw.Text("[" + t.buttonText + "]")
+	case *CheckboxElement:
+		if t.GetAttr("checked") == "true" {
+			tw.inline("[x]")
+		} else {
+			tw.inline("[ ]")
+		}
+		tw.space = true
+		tw.Elements(t.elements...)
+	case *InputElement:
+		mark := map[string]string{"checkbox": "[ ]", "radio": "( )"}[t.GetAttr("type")]
+		if len(mark) > 0 && t.GetAttr("checked") == "true" {
+			mark = mark[:1] + "x" + mark[2:]
+		}
+		// other inputs are not visible as text
+		if len(mark) > 0 {
+			tw.space = true
+			tw.inline(mark)
+			tw.space = true
+		}
+	case *LabelElement:
+		if len(t.text) > 0 {
+			tw.Text(t.text)
+		} else {
+			tw.Text(t.label)
+		}
+	case *LegendElement:
+		if len(t.text) > 0 {
+			tw.Text(t.text)
+		} else {
+			tw.Text(t.legend)
+		}
+		tw.Newline()
+	case *Title:
+		tw.Text(t.Title)
+	default:
+		tw.Elements(children(e)...)
+	}
+}
+
+// Elements renders each element in turn
+func (tw *TextWriter) Elements(elements ...Element) {
+	for _, e := range elements {
+		tw.Element(e)
+	}
+}
+
+// Text writes text, collapsing runs of whitespace into a single space as a browser would
+func (tw *TextWriter) Text(s string) {
+	if tw.preformatted {
+		tw.lines(s)
+		return
+	}
+	for len(s) > 0 {
+		i := strings.IndexFunc(s, unicode.IsSpace)
+		if i < 0 {
+			tw.inline(s)
+			return
+		}
+		if i > 0 {
+			tw.inline(s[:i])
+		}
+		tw.space = true
+		s = strings.TrimLeftFunc(s[i:], unicode.IsSpace)
+	}
+}
+
+// Newline forces a line break
+func (tw *TextWriter) Newline() {
+	if tw.nls < 0 {
+		tw.nls = 0
+	}
+	tw.sb.WriteByte('\n')
+	tw.nls++
+	tw.space = false
+}
+
+// inline writes a string which contains no line breaks
+func (tw *TextWriter) inline(s string) {
+	if len(s) == 0 {
+		return
+	}
+	if tw.nls != 0 {
+		tw.startLine()
+	} else if tw.space {
+		tw.sb.WriteByte(' ')
+	}
+	tw.space = false
+	tw.sb.WriteString(s)
+	tw.nls = 0
+}
+
+// startLine writes the indent, or list bullet, if the writer is at the start of a line
+func (tw *TextWriter) startLine() {
+	if tw.nls == 0 {
+		return
+	}
+	if len(tw.first) > 0 {
+		tw.sb.WriteString(tw.first)
+		tw.first = ""
+	} else {
+		tw.sb.WriteString(tw.indent)
+	}
+	if tw.nls < 0 {
+		tw.nls = 0
+	}
+}
+
+// block ends the current line and makes sure there are n newlines before the next text
+// n=1 starts a new line, n=2 leaves a blank line
+func (tw *TextWriter) block(n int) {
+	tw.space = false
+	if tw.nls < 0 {
+		return
+	}
+	for tw.nls < n {
+		tw.sb.WriteByte('\n')
+		tw.nls++
+	}
+}
+
+// lines writes preformatted text, line by line, keeping whitespace
+func (tw *TextWriter) lines(s string) {
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			tw.Newline()
+		}
+		tw.inline(line)
+	}
+}
+
+func (tw *TextWriter) ioReader(e *IOReaderElement) {
+	var r io.Reader = e.reader
+	if r == nil {
+		r = e.readcloser
+	}
+	b, _ := ioutil.ReadAll(r)
+	if e.readcloser != nil {
+		e.readcloser.Close()
+	}
+	tw.lines(strings.TrimRight(string(b), "\n"))
+}
+
+// heading writes the heading text on its own line, underlined
+func (tw *TextWriter) heading(h *HeadingElement) {
+	text := flatten(PlainText(h.data))
+	if len(text) == 0 {
+		return
+	}
+	rule := textHeadingRule[len(textHeadingRule)-1]
+	if h.level <= len(textHeadingRule) {
+		rule = textHeadingRule[h.level-1]
+	}
+	tw.block(2)
+	tw.inline(text)
+	tw.Newline()
+	tw.inline(strings.Repeat(string(rule), utf8.RuneCountInString(text)))
+	tw.block(2)
+}
+
+// list writes each item on its own line, prefixed by a bullet or number
+func (tw *TextWriter) list(l *ListElement) {
+	if len(l.items) == 0 {
+		return
+	}
+	n := 1
+	if start, err := strconv.Atoi(l.GetAttr("start")); err == nil {
+		n = start
+	}
+	numbering := ListNumber(l.GetAttr("type"))
+
+	// top level lists are separated by a blank line, nested lists are not
+	indent := tw.indent
+	space := 2
+	if len(indent) > 0 {
+		space = 1
+	}
+	tw.block(space)
+	for _, item := range l.items {
+		switch l.listType {
+		case Description:
+			tw.Element(item.data)
+			tw.block(1)
+			if item.desc != nil {
+				tw.indent = indent + textDescIndent
+				tw.Element(item.desc)
+				tw.block(1)
+				tw.indent = indent
+			}
+			continue
+		case Ordered:
+			tw.first = indent + listNumber(n, numbering) + ". "
+			n++
+		default:
+			tw.first = indent + textBullet
+		}
+		tw.indent = indent + strings.Repeat(" ", utf8.RuneCountInString(tw.first)-len(indent))
+		tw.Element(item.data)
+		tw.first = ""
+		tw.indent = indent
+		tw.block(1)
+	}
+	tw.block(space)
+}
+
+// table writes the table as aligned columns, with a rule under header rows
+func (tw *TextWriter) table(table *TableElement) {
+	var rows [][]string
+	var widths []int
+	var right [][]bool
+	for _, row := range table.rows {
+		cells := make([]string, len(row.cells))
+		align := make([]bool, len(row.cells))
+		for i, cell := range row.cells {
+			cells[i] = flatten(PlainText(cell.data))
+			align[i] = strings.Contains(cell.GetAttr("style"), "text-align:right")
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if w := utf8.RuneCountInString(cells[i]); w > widths[i] {
+				widths[i] = w
+			}
+		}
+		rows = append(rows, cells)
+		right = append(right, align)
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	tw.block(2)
+	for r, cells := range rows {
+		if len(cells) == 0 {
+			continue
+		}
+		line := make([]string, len(cells))
+		for i, s := range cells {
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(s))
+			if right[r][i] {
+				line[i] = pad + s
+			} else {
+				line[i] = s + pad
+			}
+		}
+		tw.lines(strings.TrimRight(strings.Join(line, textColumnSep), " "))
+		tw.Newline()
+
+		if table.rows[r].rowType == TagTh {
+			rule := make([]string, len(cells))
+			for i := range cells {
+				rule[i] = strings.Repeat("-", widths[i])
+			}
+			tw.lines(strings.Join(rule, textColumnSep))
+			tw.Newline()
+		}
+	}
+	tw.block(2)
+}
+
+// url writes the link as "name <link>"
+func (tw *TextWriter) url(u *URL) {
+	link := u.Link()
+	name := u.Name
+	if u.Element != nil {
+		name = flatten(PlainText(u.Element))
+	}
+	if len(name) == 0 || name == link {
+		tw.Text(link)
+		return
+	}
+	tw.Text(name)
+	tw.space = true
+	tw.inline("<" + link + ">")
+}
+
+// flatten joins multiple lines of text into a single line
+func flatten(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// listNumber formats n in the numbering style of an ordered list
+func listNumber(n int, numbering ListNumber) string {
+	switch numbering {
+	case LIALPHA, LIalpha:
+		s := ""
+		for ; n > 0; n = (n - 1) / 26 {
+			s = string(rune('A'+(n-1)%26)) + s
+		}
+		if numbering == LIalpha {
+			s = strings.ToLower(s)
+		}
+		return s
+	case LIROMAN, LIroman:
+		s := roman(n)
+		if numbering == LIroman {
+			s = strings.ToLower(s)
+		}
+		return s
+	}
+	return strconv.Itoa(n)
+}
+
+// roman formats n as a roman numeral
+func roman(n int) string {
+	if n <= 0 {
+		return strconv.Itoa(n)
+	}
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	symbols := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+	sb := strings.Builder{}
+	for i, v := range values {
+		for n >= v {
+			sb.WriteString(symbols[i])
+			n -= v
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,80 @@
+package html
+
+import (
+	"testing"
+)
+
+func TestPlainText(t *testing.T) {
+	doc := NewDocument()
+	doc.Head().AddTitle("Report")
+	doc.AddCSS(CSS("body { color: red; }"))
+
+	body := doc.Body()
+	body.Add(Script("alert('hi');"))
+	body.Add(Heading(1, Text("Daily Report")))
+	body.Add(P(Text("Generated   for\n  you."), Br(), Text("Second line")))
+
+	ul := List(Unordered)
+	ul.AddItem(Text("apples"))
+	ul.AddItem(Text("pears"))
+	body.Add(ul)
+
+	ol := List(Ordered)
+	ol.SetStart(3, LINumber)
+	ol.AddItem(Text("three"))
+	ol.AddItem(Text("four"))
+	body.Add(ol)
+
+	tbl := Table()
+	tbl.Header().CellStrings("Name", "Count")
+	row := tbl.Row()
+	row.CellString("widgets")
+	row.CellInt(7).Right()
+	row = tbl.Row()
+	row.CellString("gadgets")
+	row.CellInt(1234).Right()
+	body.Add(tbl)
+
+	body.Add(NewLink("http://example.com/app/page").SetName("Example"))
+
+	expected := `Daily Report
+============
+
+Generated for you.
+Second line
+
+* apples
+* pears
+
+3. three
+4. four
+
+Name     Count
+-------  -----
+widgets      7
+gadgets   1234
+
+Example <http://example.com/app/page>
+`
+	if got := PlainText(doc); got != expected {
+		t.Errorf("PlainText:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestPlainTextNestedList(t *testing.T) {
+	inner := List(Ordered)
+	inner.SetStart(1, LIalpha)
+	inner.AddItem(Text("first"))
+	inner.AddItem(Text("second"))
+
+	outer := List(Unordered)
+	outer.AddItem(Div(Text("top"), inner))
+
+	expected := `* top
+  a. first
+  b. second
+`
+	if got := PlainText(outer); got != expected {
+		t.Errorf("PlainText:\n%s\nexpected:\n%s", got, expected)
+	}
+}
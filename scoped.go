@@ -0,0 +1,79 @@
+package html
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// scopeHashLength is the number of hex digits of the hash added to scoped class names
+const scopeHashLength = 8
+
+// Scope generates class names which can not collide with classes of another scope,
+// such as one scope per package or widget
+type Scope struct {
+	hash string
+}
+
+// NewScope creates a scope, name should be unique, such as the import path of the package defining the classes
+func NewScope(name string) *Scope {
+	return &Scope{
+		hash: shortHash(name),
+	}
+}
+
+// Class creates a scoped class, named name plus the hash of the scope, with a style of defs
+func (scope *Scope) Class(name string, defs ...StyleDef) *Class {
+	return newScopedClass(name+"-"+scope.hash, defs)
+}
+
+// ScopedClass creates a class with a style of defs, named name plus a short hash of the defs,
+// so classes with the same name but different styles do not collide, and identical ones share a name
+func ScopedClass(name string, defs ...StyleDef) *Class {
+	sb := strings.Builder{}
+	for _, def := range defs {
+		sb.WriteString(def.Key + ":" + def.Value + ";")
+	}
+	return newScopedClass(name+"-"+shortHash(sb.String()), defs)
+}
+
+func newScopedClass(name string, defs []StyleDef) *Class {
+	c := NewClass(name)
+	c.style = NewStyle(name, defs...)
+	c.style.AddClass(c)
+	return c
+}
+
+// Style returns the style of a scoped class, or nil for a class created with NewClass
+// Selectors, such as hover, can be added to it with AddSelector(SelectClass(c).Hover())
+func (c *Class) Style() *Style {
+	return c.style
+}
+
+// shortHash returns the first scopeHashLength hex digits of the sha256 hash of s
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:scopeHashLength]
+}
+
+// scopedElement is an element with scoped classes, implemented by Attributes
+type scopedElement interface {
+	scopedClasses() []*Class
+}
+
+// addScopedStyles adds the styles of the scoped classes used in the document to the head,
+// each style is added once, the first time its class is seen
+func (doc *Document) addScopedStyles() {
+	Walk(doc, func(e Element) bool {
+		se, ok := e.(scopedElement)
+		if !ok {
+			return true
+		}
+		for _, c := range se.scopedClasses() {
+			if _, ok := doc.head.styles.styles[c.style.name]; !ok && !doc.head.linked[c.style.name] {
+				doc.head.styles.Add(c.style)
+			}
+		}
+		return true
+	})
+}
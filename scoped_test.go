@@ -0,0 +1,64 @@
+package html
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScopedClass(t *testing.T) {
+	billing := NewScope("example.com/billing")
+	reports := NewScope("example.com/reports")
+	a := billing.Class("header", StyleDef{Key: "color", Value: "red"})
+	b := reports.Class("header", StyleDef{Key: "color", Value: "blue"})
+	if a.Name == b.Name || !strings.HasPrefix(a.Name, "header-") || len(a.Name) != len("header-")+scopeHashLength {
+		t.Errorf("scoped names %q %q", a.Name, b.Name)
+	}
+
+	same := ScopedClass("note", StyleDef{Key: "margin", Value: "0"})
+	again := ScopedClass("note", StyleDef{Key: "margin", Value: "0"})
+	other := ScopedClass("note", StyleDef{Key: "margin", Value: "1px"})
+	if same.Name != again.Name || same.Name == other.Name {
+		t.Errorf("content names %q %q %q", same.Name, again.Name, other.Name)
+	}
+	a.Style().AddSelector(SelectClass(a).Hover())
+
+	doc := NewDocument()
+	for _, c := range []*Class{a, a, b, same, again} {
+		p := P(Text("x"))
+		p.AddClass(c)
+		doc.Body().Add(p)
+	}
+
+	html := renderString(doc.Write)
+	for _, c := range []*Class{a, b, same} {
+		if n := strings.Count(html, "<!-- Style "+c.Name+" -->"); n != 1 {
+			t.Errorf("style %s written %d times", c.Name, n)
+		}
+	}
+	if !strings.Contains(html, "."+a.Name+", ."+a.Name+":hover {") {
+		t.Errorf("missing hover selector:\n%s", html)
+	}
+	if html != renderString(doc.Write) {
+		t.Error("second render differs")
+	}
+
+	// only the style of the class an element has now is added
+	replaced := ScopedClass("replaced", StyleDef{Key: "margin", Value: "2px"})
+	named := ScopedClass("named", StyleDef{Key: "margin", Value: "3px"})
+	kept := ScopedClass("kept", StyleDef{Key: "margin", Value: "4px"})
+	p, q := P(Text("x")), P(Text("y"))
+	p.AddClass(replaced)
+	p.AddClass(kept)
+	p.AddClass(kept)
+	q.AddClass(named)
+	q.AddClassName("plain")
+	doc = NewDocument()
+	doc.Body().Add(p, q)
+	html = renderString(doc.Write)
+	if strings.Contains(html, replaced.Name) || strings.Contains(html, named.Name) || strings.Count(html, "<!-- Style "+kept.Name+" -->") != 1 {
+		t.Errorf("styles of replaced classes:\n%s", html)
+	}
+	if len(p.scopedClasses()) != 1 {
+		t.Errorf("scoped classes %v", p.scopedClasses())
+	}
+}
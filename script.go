@@ -1,8 +1,22 @@
 package html
 
+// ScriptPlace is where a script required by a component is written in the Document
+type ScriptPlace int
+
+const (
+	// ScriptBodyEnd writes the script at the end of the body, after the elements it works on
+	ScriptBodyEnd ScriptPlace = iota
+
+	// ScriptHead writes the script in the head, use with Defer or Async so it does not block the page
+	ScriptHead
+)
+
 // Head defines the HTML body element
 type ScriptElement struct {
 	Container
+
+	// place is where the script is written when added with RequireScript or Document.AddScript
+	place ScriptPlace
 }
 
 func Script(js string) *ScriptElement {
@@ -11,7 +25,135 @@ func Script(js string) *ScriptElement {
 	return s
 }
 
+// ScriptSrc creates an external script, <script src="...">
+func ScriptSrc(src string) *ScriptElement {
+	s := &ScriptElement{}
+	s.AddAttr("src", src)
+	return s
+}
+
+// Src returns the source of an external script, or "" for an inline script
+func (s *ScriptElement) Src() string {
+	return s.GetAttr("src")
+}
+
+// Module marks the script as a JavaScript module, type="module", which is deferred by default
+func (s *ScriptElement) Module() *ScriptElement {
+	s.AddAttr("type", "module")
+	return s
+}
+
+// NoModule marks the script as a fallback, only run by browsers which do not support modules
+func (s *ScriptElement) NoModule() *ScriptElement {
+	s.AddAttr("nomodule", "true")
+	return s
+}
+
+// Async runs the script as soon as it is loaded, without waiting for the page
+func (s *ScriptElement) Async() *ScriptElement {
+	s.AddAttr("async", "true")
+	return s
+}
+
+// Defer runs the script once the page has been parsed, in the order deferred scripts appear
+func (s *ScriptElement) Defer() *ScriptElement {
+	s.AddAttr("defer", "true")
+	return s
+}
+
+// Integrity sets the subresource integrity hash the browser checks the script against, see SRIHash
+func (s *ScriptElement) Integrity(hash string) *ScriptElement {
+	s.AddAttr("integrity", hash)
+	return s
+}
+
+// CrossOrigin sets the CORS mode used to fetch the script, "anonymous" or "use-credentials"
+func (s *ScriptElement) CrossOrigin(mode string) *ScriptElement {
+	s.AddAttr("crossorigin", mode)
+	return s
+}
+
+// InHead places the script in the head instead of at the end of the body,
+// when added with RequireScript or Document.AddScript
+func (s *ScriptElement) InHead() *ScriptElement {
+	s.place = ScriptHead
+	return s
+}
+
 // Write writes the HTML body tag and body data
 func (s *ScriptElement) Write(tw *TagWriter) {
 	tw.WriteTag(TagScript, s)
 }
+
+// key identifies the script for deduplication, the source of an external script or the code of an inline one
+func (s *ScriptElement) key() string {
+	if src := s.Src(); len(src) > 0 {
+		return "src:" + src
+	}
+	return "js:" + renderString(s.Container.WriteContent)
+}
+
+// RequireScript adds scripts which the elements of the container need, such as a widget library.
+// The Document writes each script once, however many containers require it, at the end of the body or in the head.
+func (c *Container) RequireScript(scripts ...*ScriptElement) {
+	c.required = append(c.required, scripts...)
+}
+
+func (c *Container) requiredScripts() []*ScriptElement {
+	return c.required
+}
+
+// scriptRequirer is an element which requires scripts, implemented by Container
+type scriptRequirer interface {
+	requiredScripts() []*ScriptElement
+}
+
+// AddScript adds scripts to the document, each is written once at the end of the body or in the head
+func (doc *Document) AddScript(scripts ...*ScriptElement) {
+	doc.scripts = append(doc.scripts, scripts...)
+}
+
+// gatherScripts collects the scripts added to the document and required by its elements,
+// and the JavaScript of the elements, and places them in the head or body ready to be written
+func (doc *Document) gatherScripts() {
+	var head, body []*ScriptElement
+	seen := make(map[string]bool)
+	add := func(scripts []*ScriptElement) {
+		for _, s := range scripts {
+			key := s.key()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if s.place == ScriptHead {
+				head = append(head, s)
+			} else {
+				body = append(body, s)
+			}
+		}
+	}
+
+	add(doc.scripts)
+	Walk(doc, func(e Element) bool {
+		if r, ok := e.(scriptRequirer); ok {
+			add(r.requiredScripts())
+		}
+		return true
+	})
+	if js := doc.gatherJavaScript().script(doc.scriptPlace); js != nil {
+		if doc.scriptPlace == ScriptHead {
+			head = append(head, js)
+		} else {
+			body = append(body, js)
+		}
+	}
+	doc.head.scripts = head
+	doc.body.scripts = body
+}
+
+// writeScripts writes scripts gathered by the Document
+func writeScripts(tw *TagWriter, scripts []*ScriptElement) {
+	for _, s := range scripts {
+		s.Write(tw)
+	}
+}
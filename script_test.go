@@ -0,0 +1,49 @@
+package html
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScriptSrc(t *testing.T) {
+	chart := func() *ScriptElement {
+		return ScriptSrc("/js/chart.js").Defer()
+	}
+
+	doc := NewDocument()
+	doc.AddScript(ScriptSrc("/js/app.mjs").Module().InHead(), ScriptSrc("/js/legacy.js").NoModule().InHead())
+	for i := 0; i < 3; i++ {
+		widget := Div(Text("widget"))
+		widget.RequireScript(chart())
+		doc.Body().Add(widget)
+	}
+	doc.Body().Add(Text("last"))
+	cdn := ScriptSrc("https://cdn.example.com/lib.js").Async().Integrity("sha384-abc").CrossOrigin("anonymous")
+	doc.AddScript(cdn, cdn)
+
+	html := renderString(doc.Write)
+	if n := strings.Count(html, `src="/js/chart.js"`); n != 1 {
+		t.Errorf("chart.js written %d times:\n%s", n, html)
+	}
+	for _, s := range []string{
+		`<script src="/js/app.mjs" type="module"></script>`,
+		`<script nomodule src="/js/legacy.js"></script>`,
+		`<script async crossorigin="anonymous" integrity="sha384-abc" src="https://cdn.example.com/lib.js"></script>`,
+		`<script defer src="/js/chart.js"></script>`,
+	} {
+		if !strings.Contains(html, s) {
+			t.Errorf("missing %s in:\n%s", s, html)
+		}
+	}
+
+	head := html[:strings.Index(html, "</head>")]
+	if !strings.Contains(head, "app.mjs") || strings.Contains(head, "chart.js") {
+		t.Errorf("head scripts:\n%s", head)
+	}
+	if strings.Index(html, "chart.js") < strings.Index(html, "last") {
+		t.Errorf("body scripts are not at the end:\n%s", html)
+	}
+	if html != renderString(doc.Write) {
+		t.Error("second render differs")
+	}
+}
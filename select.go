@@ -0,0 +1,188 @@
+package html
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Multiple allows more than one option to be selected
+func (e *FormSelectElement) Multiple() *FormSelectElement {
+	e.AddAttr("multiple", "true")
+	return e
+}
+
+// Size sets the number of options shown at once, making the select a list box
+func (e *FormSelectElement) Size(n int) *FormSelectElement {
+	e.AddAttr("size", strconv.Itoa(n))
+	return e
+}
+
+// Placeholder adds a first option with the text, which can not be chosen and is shown while no option is selected
+// Use it with the Required validator to make the user choose
+func (e *FormSelectElement) Placeholder(text string) *FormSelectElement {
+	e.placeholder = &OptionElement{display: text}
+	e.placeholder.AddAttr("value", "")
+	e.placeholder.AddAttr("disabled", "true")
+	return e
+}
+
+// SetValue sets the current values of the select. Options with the values are selected, the others are not,
+// including options added later.
+func (e *FormSelectElement) SetValue(values ...string) *FormSelectElement {
+	e.values = values
+	for _, opt := range e.options {
+		opt.Selected(containsString(values, opt.GetAttr("value")))
+	}
+	return e
+}
+
+// Selected returns the values of the selected options
+func (e *FormSelectElement) Selected() []string {
+	var values []string
+	for _, opt := range e.options {
+		if opt.GetAttr("selected") == "true" {
+			values = append(values, opt.GetAttr("value"))
+		}
+	}
+	return values
+}
+
+// Group adds an option group with the label, add its options with OptGroupElement.Option
+func (e *FormSelectElement) Group(label string) *OptGroupElement {
+	g := &OptGroupElement{sel: e}
+	g.AddTextAttr("label", label)
+	e.items = append(e.items, g)
+	return g
+}
+
+// textOption adds an option with display text and a value from data, which are escaped when written
+func (e *FormSelectElement) textOption(display, value string) *OptionElement {
+	opt := e.Option(html.EscapeString(display), value)
+	opt.AddTextAttr("value", value)
+	return opt
+}
+
+// group returns the option group with the label, adding it if there is none
+func (e *FormSelectElement) group(label string) *OptGroupElement {
+	for _, item := range e.items {
+		if g, ok := item.(*OptGroupElement); ok && g.GetAttr("label") == label {
+			return g
+		}
+	}
+	return e.Group(label)
+}
+
+// OptionsFromMap adds an option for each entry of m, a map of values to display text, ordered by display text
+// Keys and values are formatted like the values of a generated form, the display text is escaped
+// m which is not a map is an error.
+func (e *FormSelectElement) OptionsFromMap(m interface{}) error {
+	rv := reflect.ValueOf(m)
+	if rv.Kind() != reflect.Map {
+		return fmt.Errorf("select: %T is not a map", m)
+	}
+	type option struct{ display, value string }
+	var options []option
+	iter := rv.MapRange()
+	for iter.Next() {
+		options = append(options, option{display: formatValue(iter.Value(), ""), value: formatValue(iter.Key(), "")})
+	}
+	sort.Slice(options, func(i, j int) bool {
+		if options[i].display == options[j].display {
+			return options[i].value < options[j].value
+		}
+		return options[i].display < options[j].display
+	})
+	for _, opt := range options {
+		e.textOption(opt.display, opt.value)
+	}
+	return nil
+}
+
+// OptionsFromSlice adds an option for each item of slice, in order. value and display are called with each item,
+// such as a struct, and return the value and the display text of its option, which is escaped.
+// slice which is not a slice or array is an error.
+func (e *FormSelectElement) OptionsFromSlice(slice interface{}, value func(item interface{}) string, display func(item interface{}) string) error {
+	rv := reflect.ValueOf(slice)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Errorf("select: %T is not a slice", slice)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		item := rv.Index(i).Interface()
+		e.textOption(display(item), value(item))
+	}
+	return nil
+}
+
+// OptionsFromRows adds an option for each row of a query. The first column is the value, the second the display text,
+// the value is also shown when there is no second column. A third column is the label of the option group of the row.
+// NULLs are empty, and the display text is escaped.
+func (e *FormSelectElement) OptionsFromRows(rows *sql.Rows) error {
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if len(cols) < 1 || len(cols) > 3 {
+		return fmt.Errorf("select: query has %d columns, want 1 to 3", len(cols))
+	}
+	fields := make([]sql.NullString, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range fields {
+		dest[i] = &fields[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		value, display := fields[0].String, fields[0].String
+		if len(cols) > 1 {
+			display = fields[1].String
+		}
+		if len(cols) > 2 {
+			e.group(fields[2].String).textOption(display, value)
+		} else {
+			e.textOption(display, value)
+		}
+	}
+	return rows.Err()
+}
+
+// OptGroupElement is a labelled group of options in a select
+type OptGroupElement struct {
+	Attributes
+
+	sel     *FormSelectElement
+	options []*OptionElement
+}
+
+// Option adds an option to the group, which is selected if value is a current value of the select
+func (g *OptGroupElement) Option(display, value string) *OptionElement {
+	opt := g.sel.newOption(display, value)
+	g.options = append(g.options, opt)
+	return opt
+}
+
+// textOption adds an option to the group like FormSelectElement.textOption
+func (g *OptGroupElement) textOption(display, value string) *OptionElement {
+	opt := g.Option(html.EscapeString(display), value)
+	opt.AddTextAttr("value", value)
+	return opt
+}
+
+// Disabled stops the options of the group being chosen
+func (g *OptGroupElement) Disabled() *OptGroupElement {
+	g.AddAttr("disabled", "true")
+	return g
+}
+
+func (g *OptGroupElement) Write(tw *TagWriter) {
+	tw.WriteTag(TagOptGroup, g)
+}
+func (g *OptGroupElement) WriteContent(tw *TagWriter) {
+	for _, opt := range g.options {
+		opt.Write(tw)
+	}
+}
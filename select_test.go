@@ -0,0 +1,132 @@
+package html
+
+import (
+	"database/sql/driver"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormSelect(t *testing.T) {
+	sel := FormSelect("fruit").Multiple().Size(4).Placeholder("Choose fruit").SetValue("b", "c")
+	sel.Option("Apple", "a")
+	sel.Option("Banana", "b")
+	citrus := sel.Group("Citrus")
+	citrus.Option("Clementine", "c")
+	citrus.Option("Lemon", "l")
+
+	expected := `<select multiple name="fruit" size="4"><option disabled value="">Choose fruit</option>
+<option value="a">Apple</option>
+<option selected value="b">Banana</option>
+<optgroup label="Citrus"><option selected value="c">Clementine</option>
+<option value="l">Lemon</option>
+</optgroup>
+</select>
+`
+	if got := renderString(sel.Write); got != expected {
+		t.Errorf("got\n%s\nexpected\n%s", got, expected)
+	}
+
+	sel.SetValue()
+	if got := renderString(sel.Write); !strings.Contains(got, `<option disabled selected value="">Choose fruit</option>`) {
+		t.Errorf("placeholder not selected in\n%s", got)
+	}
+
+	f := Form(NewLink("/")).SetName("f")
+	f.Add(sel)
+	f.SetValues(url.Values{"fruit": {"a", "l"}})
+	if got := sel.Selected(); !reflect.DeepEqual(got, []string{"a", "l"}) {
+		t.Errorf("selected %v after SetValues", got)
+	}
+}
+
+func TestSelectOptionsFrom(t *testing.T) {
+	sel := FormSelect("n").SetValue("2")
+	if err := sel.OptionsFromMap(map[int]string{3: "three", 1: "one", 2: "two"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := optionValues(sel); !reflect.DeepEqual(got, []string{"one=1", "three=3", "*two=2"}) {
+		t.Errorf("from map %v", got)
+	}
+
+	type user struct {
+		ID   int
+		Name string
+	}
+	users := []user{{7, "Ann"}, {9, "Bob"}}
+	sel = FormSelect("user").SetValue("9")
+	err := sel.OptionsFromSlice(users,
+		func(u interface{}) string { return formatValue(reflect.ValueOf(u.(user).ID), "") },
+		func(u interface{}) string { return u.(user).Name })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := optionValues(sel); !reflect.DeepEqual(got, []string{"Ann=7", "*Bob=9"}) {
+		t.Errorf("from slice %v", got)
+	}
+
+	rows := testQuery(t, []string{"id", "name", "team"},
+		[]driver.Value{int64(1), "Ann", "Red"},
+		[]driver.Value{int64(2), "Bob", "Blue"},
+		[]driver.Value{int64(3), nil, "Red"},
+	)
+	sel = FormSelect("player").SetValue("3")
+	if err := sel.OptionsFromRows(rows); err != nil {
+		t.Fatal(err)
+	}
+	if got := optionValues(sel); !reflect.DeepEqual(got, []string{"Ann=1", "Bob=2", "*=3"}) {
+		t.Errorf("from rows %v", got)
+	}
+	if len(sel.items) != 2 || sel.items[0].GetAttr("label") != "Red" {
+		t.Errorf("groups %v", sel.items)
+	}
+
+	if err := FormSelect("x").OptionsFromMap([]string{"a"}); err == nil {
+		t.Error("expected error for a slice as a map")
+	}
+	if err := FormSelect("x").OptionsFromSlice(map[string]string{}, nil, nil); err == nil {
+		t.Error("expected error for a map as a slice")
+	}
+}
+
+// optionValues returns display=value of each option, with a * for selected options
+func optionValues(sel *FormSelectElement) []string {
+	var got []string
+	for _, opt := range sel.options {
+		s := opt.display + "=" + opt.GetAttr("value")
+		if opt.GetAttr("selected") == "true" {
+			s = "*" + s
+		}
+		got = append(got, s)
+	}
+	return got
+}
+
+func TestSelectOptionsEscaped(t *testing.T) {
+	sel := FormSelect("m")
+	if err := sel.OptionsFromMap(map[string]string{"a": "<script>"}); err != nil {
+		t.Fatal(err)
+	}
+	err := sel.OptionsFromSlice([]string{"b&c"},
+		func(s interface{}) string { return s.(string) },
+		func(s interface{}) string { return s.(string) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := testQuery(t, []string{"id", "name"}, []driver.Value{"d", "Tom & <Jerry>"})
+	if err := sel.OptionsFromRows(rows); err != nil {
+		t.Fatal(err)
+	}
+
+	html := renderString(sel.Write)
+	for _, expected := range []string{
+		`<option value="a">&lt;script&gt;</option>`,
+		`<option value="b&amp;c">b&amp;c</option>`,
+		`<option value="d">Tom &amp; &lt;Jerry&gt;</option>`,
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("missing %s in\n%s", expected, html)
+		}
+	}
+}
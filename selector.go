@@ -0,0 +1,1091 @@
+package html
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// selector is a parsed complex CSS selector, such as "table.center > tr:first-child td"
+type selector struct {
+	// text is the selector as written
+	text string
+
+	// compounds are in source order, combinators[i] joins compounds[i] and compounds[i+1]
+	// a combinator is one of ' ' (descendant), '>' (child), '+' (next sibling) or '~' (subsequent sibling)
+	compounds   []*compoundSelector
+	combinators []byte
+}
+
+// compoundSelector is a sequence of simple selectors that all apply to one element, such as "td.total:last-child"
+type compoundSelector struct {
+	// tag is the element name, "" or "*" matches any element
+	tag           string
+	ids           []string
+	classes       []string
+	attrs         []attrSelector
+	pseudos       []pseudoClass
+	pseudoElement string
+}
+
+// attrSelector matches an element attribute, [name], [name=value], [name^=value] ...
+type attrSelector struct {
+	name  string
+	op    string
+	value string
+
+	// fold is set by the i flag, values are compared case insensitively
+	fold bool
+}
+
+// pseudoClass is a pseudo-class such as :first-child or :nth-child(2n+1)
+type pseudoClass struct {
+	name string
+	arg  string
+
+	// sel is the parsed argument of :not(), :is() and :where()
+	sel []*selector
+
+	// a and b are the parsed argument of the :nth-*() pseudo-classes, an+b
+	a, b int
+}
+
+// structuralPseudos can be resolved from the position of an element in the tree, so rules using them can be inlined
+// Any other pseudo-class (:hover, :focus, :visited ...) depends on the state of the browser
+var structuralPseudos = map[string]bool{
+	"root":             true,
+	"first-child":      true,
+	"last-child":       true,
+	"only-child":       true,
+	"first-of-type":    true,
+	"last-of-type":     true,
+	"only-of-type":     true,
+	"nth-child":        true,
+	"nth-last-child":   true,
+	"nth-of-type":      true,
+	"nth-last-of-type": true,
+	"not":              true,
+	"is":               true,
+	"where":            true,
+}
+
+// selectorParser is a parser for selector lists
+type selectorParser struct {
+	s   string
+	pos int
+}
+
+// parseSelectorList parses a comma separated list of selectors
+func parseSelectorList(s string) ([]*selector, error) {
+	p := &selectorParser{s: s}
+	sels, err := p.selectorList()
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", p.peek())
+	}
+	return sels, nil
+}
+
+func (p *selectorParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("selector %q: offset %d: %s", p.s, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *selectorParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *selectorParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+// skipSpace skips whitespace and returns true if any was found
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for !p.eof() && isCSSSpace(p.peek()) {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+// selectorList parses selectors until the end of input or a closing )
+func (p *selectorParser) selectorList() ([]*selector, error) {
+	var sels []*selector
+	for {
+		p.skipSpace()
+		start := p.pos
+		sel, err := p.complexSelector()
+		if err != nil {
+			return nil, err
+		}
+		sel.text = strings.TrimSpace(p.s[start:p.pos])
+		sels = append(sels, sel)
+		if p.peek() != ',' {
+			return sels, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *selectorParser) complexSelector() (*selector, error) {
+	sel := &selector{}
+	for {
+		c, err := p.compoundSelector()
+		if err != nil {
+			return nil, err
+		}
+		sel.compounds = append(sel.compounds, c)
+
+		space := p.skipSpace()
+		if p.eof() || p.peek() == ',' || p.peek() == ')' {
+			return sel, nil
+		}
+		combinator := byte(' ')
+		switch p.peek() {
+		case '>', '+', '~':
+			combinator = p.peek()
+			p.pos++
+			p.skipSpace()
+		default:
+			if !space {
+				return nil, p.errorf("unexpected %q", p.peek())
+			}
+		}
+		sel.combinators = append(sel.combinators, combinator)
+	}
+}
+
+func (p *selectorParser) compoundSelector() (*compoundSelector, error) {
+	c := &compoundSelector{}
+	start := p.pos
+	if p.peek() == '*' {
+		c.tag = "*"
+		p.pos++
+	} else if p.identStart() {
+		c.tag = strings.ToLower(p.ident())
+	}
+	for !p.eof() {
+		if len(c.pseudoElement) > 0 && strings.IndexByte("#.[", p.peek()) >= 0 {
+			return nil, p.errorf("pseudo-element must be last")
+		}
+		switch p.peek() {
+		case '#':
+			p.pos++
+			id := p.ident()
+			if len(id) == 0 {
+				return nil, p.errorf("missing id")
+			}
+			c.ids = append(c.ids, id)
+		case '.':
+			p.pos++
+			class := p.ident()
+			if len(class) == 0 {
+				return nil, p.errorf("missing class name")
+			}
+			c.classes = append(c.classes, class)
+		case '[':
+			p.pos++
+			attr, err := p.attrSelector()
+			if err != nil {
+				return nil, err
+			}
+			c.attrs = append(c.attrs, attr)
+		case ':':
+			p.pos++
+			if p.peek() == ':' {
+				p.pos++
+				name := p.ident()
+				if len(name) == 0 {
+					return nil, p.errorf("missing pseudo-element")
+				}
+				c.pseudoElement = strings.ToLower(name)
+				continue
+			}
+			pseudo, err := p.pseudoClass()
+			if err != nil {
+				return nil, err
+			}
+			// CSS2 pseudo-elements may be written with a single colon
+			switch pseudo.name {
+			case "before", "after", "first-line", "first-letter":
+				c.pseudoElement = pseudo.name
+			default:
+				c.pseudos = append(c.pseudos, pseudo)
+			}
+		default:
+			if p.pos == start {
+				return nil, p.errorf("expected selector")
+			}
+			return c, nil
+		}
+	}
+	if p.pos == start {
+		return nil, p.errorf("expected selector")
+	}
+	return c, nil
+}
+
+func (p *selectorParser) attrSelector() (attrSelector, error) {
+	var attr attrSelector
+	p.skipSpace()
+	attr.name = strings.ToLower(p.ident())
+	if len(attr.name) == 0 {
+		return attr, p.errorf("missing attribute name")
+	}
+	p.skipSpace()
+	if p.peek() == ']' {
+		p.pos++
+		return attr, nil
+	}
+	if strings.IndexByte("~|^$*", p.peek()) >= 0 {
+		attr.op = p.s[p.pos : p.pos+1]
+		p.pos++
+	}
+	if p.peek() != '=' {
+		return attr, p.errorf("expected = in attribute selector")
+	}
+	attr.op += "="
+	p.pos++
+	p.skipSpace()
+
+	switch p.peek() {
+	case '"', '\'':
+		s, err := p.quoted()
+		if err != nil {
+			return attr, err
+		}
+		attr.value = s
+	default:
+		attr.value = p.ident()
+		if len(attr.value) == 0 {
+			return attr, p.errorf("missing attribute value")
+		}
+	}
+	p.skipSpace()
+	switch p.peek() {
+	case 'i', 'I':
+		attr.fold = true
+		p.pos++
+	case 's', 'S':
+		p.pos++
+	}
+	p.skipSpace()
+	if p.peek() != ']' {
+		return attr, p.errorf("expected ]")
+	}
+	p.pos++
+	return attr, nil
+}
+
+func (p *selectorParser) pseudoClass() (pseudoClass, error) {
+	pseudo := pseudoClass{name: strings.ToLower(p.ident())}
+	if len(pseudo.name) == 0 {
+		return pseudo, p.errorf("missing pseudo-class")
+	}
+	if p.peek() != '(' {
+		return pseudo, nil
+	}
+	p.pos++
+
+	start := p.pos
+	switch pseudo.name {
+	case "not", "is", "where":
+		sels, err := p.selectorList()
+		if err != nil {
+			return pseudo, err
+		}
+		pseudo.sel = sels
+	default:
+		depth := 1
+		for ; !p.eof(); p.pos++ {
+			if c := p.peek(); c == '(' {
+				depth++
+			} else if c == ')' {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+	}
+	if p.peek() != ')' {
+		return pseudo, p.errorf("expected )")
+	}
+	pseudo.arg = strings.TrimSpace(p.s[start:p.pos])
+	p.pos++
+
+	if strings.HasPrefix(pseudo.name, "nth-") {
+		a, b, ok := parseNth(pseudo.arg)
+		if !ok {
+			return pseudo, p.errorf("invalid argument %q to :%s", pseudo.arg, pseudo.name)
+		}
+		pseudo.a, pseudo.b = a, b
+	}
+	return pseudo, nil
+}
+
+// identStart returns true if an identifier starts at the current position
+func (p *selectorParser) identStart() bool {
+	if p.eof() {
+		return false
+	}
+	c := p.peek()
+	if c == '-' && p.pos+1 < len(p.s) {
+		c = p.s[p.pos+1]
+	}
+	return isNameStart(c) || c == '\\' || c == '-'
+}
+
+// ident parses an identifier, resolving escapes
+func (p *selectorParser) ident() string {
+	sb := strings.Builder{}
+	for !p.eof() {
+		c := p.peek()
+		switch {
+		case c == '\\':
+			p.pos++
+			sb.WriteString(p.escape())
+		case isNameChar(c):
+			sb.WriteByte(c)
+			p.pos++
+		default:
+			return sb.String()
+		}
+	}
+	return sb.String()
+}
+
+// escape parses the character after a \, either up to 6 hex digits and an optional space, or any single character
+func (p *selectorParser) escape() string {
+	start := p.pos
+	for p.pos < len(p.s) && p.pos-start < 6 && isHex(p.s[p.pos]) {
+		p.pos++
+	}
+	if p.pos > start {
+		n, _ := strconv.ParseUint(p.s[start:p.pos], 16, 32)
+		if !p.eof() && isCSSSpace(p.peek()) {
+			p.pos++
+		}
+		if n == 0 || n > utf8.MaxRune {
+			return string(utf8.RuneError)
+		}
+		return string(rune(n))
+	}
+	if p.eof() {
+		return ""
+	}
+	r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+	p.pos += size
+	return string(r)
+}
+
+// quoted parses a single or double quoted string
+func (p *selectorParser) quoted() (string, error) {
+	quote := p.peek()
+	p.pos++
+	sb := strings.Builder{}
+	for !p.eof() {
+		c := p.peek()
+		switch c {
+		case quote:
+			p.pos++
+			return sb.String(), nil
+		case '\\':
+			p.pos++
+			sb.WriteString(p.escape())
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// parseNth parses the an+b argument of :nth-child() and friends
+func parseNth(s string) (int, int, bool) {
+	s = strings.ToLower(strings.Join(strings.Fields(s), ""))
+	switch s {
+	case "odd":
+		return 2, 1, true
+	case "even":
+		return 2, 0, true
+	}
+	i := strings.IndexByte(s, 'n')
+	if i < 0 {
+		b, err := strconv.Atoi(s)
+		return 0, b, err == nil
+	}
+	a := 1
+	switch as := s[:i]; as {
+	case "", "+":
+	case "-":
+		a = -1
+	default:
+		n, err := strconv.Atoi(as)
+		if err != nil {
+			return 0, 0, false
+		}
+		a = n
+	}
+	b := 0
+	if bs := s[i+1:]; len(bs) > 0 {
+		if bs[0] != '+' && bs[0] != '-' {
+			return 0, 0, false
+		}
+		n, err := strconv.Atoi(bs)
+		if err != nil {
+			return 0, 0, false
+		}
+		b = n
+	}
+	return a, b, true
+}
+
+func isCSSSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isNameStart(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c >= 0x80
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9') || c == '-'
+}
+
+// inlinable returns true if the selector can be resolved without a browser, it has no pseudo-elements
+// and only structural pseudo-classes
+func (sel *selector) inlinable() bool {
+	for _, c := range sel.compounds {
+		if len(c.pseudoElement) > 0 {
+			return false
+		}
+		for _, pseudo := range c.pseudos {
+			if !structuralPseudos[pseudo.name] {
+				return false
+			}
+			for _, s := range pseudo.sel {
+				if !s.inlinable() {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// specificity returns the selector specificity as (ids, classes, elements)
+func (sel *selector) specificity() [3]int {
+	var spec [3]int
+	for _, c := range sel.compounds {
+		spec[0] += len(c.ids)
+		spec[1] += len(c.classes) + len(c.attrs)
+		if len(c.tag) > 0 && c.tag != "*" {
+			spec[2]++
+		}
+		if len(c.pseudoElement) > 0 {
+			spec[2]++
+		}
+		for _, pseudo := range c.pseudos {
+			switch pseudo.name {
+			case "where":
+			case "not", "is":
+				// the specificity of the most specific argument
+				var max [3]int
+				for _, s := range pseudo.sel {
+					if ss := s.specificity(); compareSpecificity(ss, max) > 0 {
+						max = ss
+					}
+				}
+				for i := range spec {
+					spec[i] += max[i]
+				}
+			default:
+				spec[1]++
+			}
+		}
+	}
+	return spec
+}
+
+// compareSpecificity returns -1, 0 or 1 if a is less than, equal to or greater than b
+func compareSpecificity(a [3]int, b [3]int) int {
+	for i := range a {
+		if a[i] < b[i] {
+			return -1
+		}
+		if a[i] > b[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+// styleNode is an element in the tree used for selector matching
+type styleNode struct {
+	e        Element
+	tag      string
+	parent   *styleNode
+	children []*styleNode
+	index    int
+}
+
+// newStyleTree builds the element tree for e, elements without a tag (Text) are left out
+// and their children are attached to the closest tagged ancestor
+func newStyleTree(e Element) *styleNode {
+	root := &styleNode{e: e, tag: tagName(e)}
+	root.addChildren(e)
+	return root
+}
+
+func (n *styleNode) addChildren(e Element) {
+	for _, child := range children(e) {
+		tag := tagName(child)
+		if len(tag) == 0 {
+			n.addChildren(child)
+			continue
+		}
+		node := &styleNode{e: child, tag: tag, parent: n, index: len(n.children)}
+		n.children = append(n.children, node)
+		node.addChildren(child)
+	}
+}
+
+// walk calls fn for each node in document order
+func (n *styleNode) walk(fn func(n *styleNode)) {
+	fn(n)
+	for _, child := range n.children {
+		child.walk(fn)
+	}
+}
+
+// siblings returns the element siblings of n, including n
+func (n *styleNode) siblings() []*styleNode {
+	if n.parent == nil {
+		return []*styleNode{n}
+	}
+	return n.parent.children
+}
+
+// match returns true if the selector matches the node
+func (sel *selector) match(n *styleNode) bool {
+	return sel.matchAt(n, len(sel.compounds)-1)
+}
+
+func (sel *selector) matchAt(n *styleNode, i int) bool {
+	if !sel.compounds[i].match(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	switch sel.combinators[i-1] {
+	case '>':
+		return n.parent != nil && sel.matchAt(n.parent, i-1)
+	case '+':
+		return n.index > 0 && sel.matchAt(n.siblings()[n.index-1], i-1)
+	case '~':
+		for _, s := range n.siblings()[:n.index] {
+			if sel.matchAt(s, i-1) {
+				return true
+			}
+		}
+	default:
+		for p := n.parent; p != nil; p = p.parent {
+			if sel.matchAt(p, i-1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *compoundSelector) match(n *styleNode) bool {
+	if len(c.tag) > 0 && c.tag != "*" && c.tag != n.tag {
+		return false
+	}
+	for _, id := range c.ids {
+		if n.e.GetAttr("id") != id {
+			return false
+		}
+	}
+	if len(c.classes) > 0 {
+		classes := strings.Fields(n.e.GetAttr("class"))
+		for _, class := range c.classes {
+			if !containsString(classes, class) {
+				return false
+			}
+		}
+	}
+	for _, attr := range c.attrs {
+		if !attr.match(n.e) {
+			return false
+		}
+	}
+	for _, pseudo := range c.pseudos {
+		if !pseudo.match(n) {
+			return false
+		}
+	}
+	return true
+}
+
+func (attr *attrSelector) match(e Element) bool {
+	v, ok := attributeValue(e, attr.name)
+	if !ok {
+		return false
+	}
+	want := attr.value
+	if attr.fold {
+		v = strings.ToLower(v)
+		want = strings.ToLower(want)
+	}
+	switch attr.op {
+	case "":
+		return true
+	case "=":
+		return v == want
+	case "~=":
+		return containsString(strings.Fields(v), want)
+	case "|=":
+		return v == want || strings.HasPrefix(v, want+"-")
+	case "^=":
+		return len(want) > 0 && strings.HasPrefix(v, want)
+	case "$=":
+		return len(want) > 0 && strings.HasSuffix(v, want)
+	case "*=":
+		return len(want) > 0 && strings.Contains(v, want)
+	}
+	return false
+}
+
+// attributeValue returns the value of an attribute as it will be rendered, boolean attributes set to "false" are not rendered
+func attributeValue(e Element, name string) (string, bool) {
+	if u, ok := e.(*URL); ok && name == "href" {
+		// href is only set when the link is written
+		return u.Link(), true
+	}
+	v := e.GetAttr(name)
+	switch v {
+	case "":
+		// GetAttr does not tell an empty value from a missing one, check the rendered attributes
+		return "", strings.Contains(e.GetAttrs()+" ", " "+name+`="" `)
+	case "false":
+		return "", false
+	case "true":
+		return "", true
+	}
+	return v, true
+}
+
+func (pseudo *pseudoClass) match(n *styleNode) bool {
+	switch pseudo.name {
+	case "root":
+		return n.parent == nil
+	case "first-child":
+		return n.index == 0
+	case "last-child":
+		return n.index == len(n.siblings())-1
+	case "only-child":
+		return len(n.siblings()) == 1
+	case "first-of-type", "last-of-type", "only-of-type":
+		pos, count := n.typePosition()
+		switch pseudo.name {
+		case "first-of-type":
+			return pos == 1
+		case "last-of-type":
+			return pos == count
+		}
+		return count == 1
+	case "nth-child":
+		return nthMatch(pseudo.a, pseudo.b, n.index+1)
+	case "nth-last-child":
+		return nthMatch(pseudo.a, pseudo.b, len(n.siblings())-n.index)
+	case "nth-of-type":
+		pos, _ := n.typePosition()
+		return nthMatch(pseudo.a, pseudo.b, pos)
+	case "nth-last-of-type":
+		pos, count := n.typePosition()
+		return nthMatch(pseudo.a, pseudo.b, count-pos+1)
+	case "not":
+		for _, s := range pseudo.sel {
+			if s.match(n) {
+				return false
+			}
+		}
+		return true
+	case "is", "where":
+		for _, s := range pseudo.sel {
+			if s.match(n) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// typePosition returns the 1 based position of n amongst siblings with the same tag, and the number of those siblings
+func (n *styleNode) typePosition() (int, int) {
+	pos, count := 0, 0
+	for _, s := range n.siblings() {
+		if s.tag == n.tag {
+			count++
+			if s == n {
+				pos = count
+			}
+		}
+	}
+	return pos, count
+}
+
+// nthMatch returns true if pos is a+n*b for some n >= 0
+func nthMatch(a int, b int, pos int) bool {
+	if a == 0 {
+		return pos == b
+	}
+	n := pos - b
+	return n%a == 0 && n/a >= 0
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// AttrOp is the comparison used by an attribute selector
+type AttrOp string
+
+const (
+	AttrEquals    = AttrOp("=")  // [attr=value]
+	AttrIncludes  = AttrOp("~=") // [attr~=value] value is one of a whitespace separated list
+	AttrDashMatch = AttrOp("|=") // [attr|=value] value, or starts with value-
+	AttrPrefix    = AttrOp("^=") // [attr^=value]
+	AttrSuffix    = AttrOp("$=") // [attr$=value]
+	AttrSubstring = AttrOp("*=") // [attr*=value]
+)
+
+var (
+	// dynamicPseudos are pseudo-classes which depend on the state of the browser
+	dynamicPseudos = map[string]bool{
+		"active": true, "any-link": true, "checked": true, "default": true, "disabled": true, "empty": true,
+		"enabled": true, "focus": true, "focus-visible": true, "focus-within": true, "hover": true,
+		"in-range": true, "indeterminate": true, "invalid": true, "link": true, "optional": true,
+		"out-of-range": true, "placeholder-shown": true, "read-only": true, "read-write": true,
+		"required": true, "target": true, "valid": true, "visited": true,
+	}
+
+	pseudoElements = map[string]bool{
+		"after": true, "backdrop": true, "before": true, "file-selector-button": true, "first-letter": true,
+		"first-line": true, "marker": true, "placeholder": true, "selection": true,
+	}
+)
+
+// Selector builds a CSS selector which can be associated with a Style.
+// Names are escaped as they are added, and the complete selector is validated, use Err to check for errors.
+//
+//	Select("table").Class(c).Child(Select("tr").NthChild("odd")).Descendant(Select("a").Hover())
+//
+// renders as
+//
+//	table.c > tr:nth-child(odd) a:hover
+type Selector struct {
+	sb  strings.Builder
+	err error
+
+	// pseudoElement is set once a pseudo-element is added, nothing can follow it in the compound
+	pseudoElement bool
+}
+
+// Select starts a selector for an element name, use "" or "*" to match any element
+func Select(element string) *Selector {
+	sel := &Selector{}
+	switch {
+	case element == "" || element == "*":
+		sel.sb.WriteString("*")
+	case isElementName(element):
+		sel.sb.WriteString(strings.ToLower(element))
+	default:
+		sel.errorf("invalid element name %q", element)
+	}
+	return sel
+}
+
+// SelectClass starts a selector for a class
+func SelectClass(c *Class) *Selector {
+	return (&Selector{}).Class(c)
+}
+
+// SelectID starts a selector for an element id
+func SelectID(id string) *Selector {
+	return (&Selector{}).ID(id)
+}
+
+func (sel *Selector) errorf(format string, args ...interface{}) *Selector {
+	if sel.err == nil {
+		sel.err = fmt.Errorf("selector: "+format, args...)
+	}
+	return sel
+}
+
+// simple adds a simple selector to the current compound
+func (sel *Selector) simple(s string) *Selector {
+	if sel.pseudoElement {
+		return sel.errorf("%q can not follow a pseudo-element", s)
+	}
+	sel.sb.WriteString(s)
+	return sel
+}
+
+// Class adds a class to the current compound selector
+func (sel *Selector) Class(c *Class) *Selector {
+	if c == nil {
+		return sel.errorf("nil class")
+	}
+	return sel.ClassName(c.Name)
+}
+
+// ClassName adds a class, by name, to the current compound selector
+func (sel *Selector) ClassName(name string) *Selector {
+	if len(name) == 0 {
+		return sel.errorf("empty class name")
+	}
+	return sel.simple("." + CSSEscape(name))
+}
+
+// ID adds an id to the current compound selector
+func (sel *Selector) ID(id string) *Selector {
+	if len(id) == 0 {
+		return sel.errorf("empty id")
+	}
+	return sel.simple("#" + CSSEscape(id))
+}
+
+// Attr matches elements which have the attribute name
+func (sel *Selector) Attr(name string) *Selector {
+	if len(name) == 0 {
+		return sel.errorf("empty attribute name")
+	}
+	return sel.simple("[" + CSSEscape(name) + "]")
+}
+
+// AttrMatch matches elements with an attribute value, [name op "value"]
+func (sel *Selector) AttrMatch(name string, op AttrOp, value string) *Selector {
+	if len(name) == 0 {
+		return sel.errorf("empty attribute name")
+	}
+	switch op {
+	case AttrEquals, AttrIncludes, AttrDashMatch, AttrPrefix, AttrSuffix, AttrSubstring:
+	default:
+		return sel.errorf("invalid attribute operator %q", op)
+	}
+	return sel.simple("[" + CSSEscape(name) + string(op) + CSSString(value) + "]")
+}
+
+// Pseudo adds a pseudo-class without an argument, such as "hover" or "first-child"
+func (sel *Selector) Pseudo(name string) *Selector {
+	name = strings.ToLower(name)
+	if !dynamicPseudos[name] && !structuralPseudos[name] {
+		return sel.errorf("unknown pseudo-class :%s", name)
+	}
+	return sel.simple(":" + name)
+}
+
+// nth adds one of the :nth-*() pseudo-classes
+func (sel *Selector) nth(name string, expr string) *Selector {
+	if _, _, ok := parseNth(expr); !ok {
+		return sel.errorf("invalid argument %q to :%s", expr, name)
+	}
+	return sel.simple(":" + name + "(" + strings.Join(strings.Fields(expr), "") + ")")
+}
+
+// Hover adds :hover
+func (sel *Selector) Hover() *Selector {
+	return sel.Pseudo("hover")
+}
+
+// Focus adds :focus
+func (sel *Selector) Focus() *Selector {
+	return sel.Pseudo("focus")
+}
+
+// Active adds :active
+func (sel *Selector) Active() *Selector {
+	return sel.Pseudo("active")
+}
+
+// Visited adds :visited
+func (sel *Selector) Visited() *Selector {
+	return sel.Pseudo("visited")
+}
+
+// FirstChild adds :first-child
+func (sel *Selector) FirstChild() *Selector {
+	return sel.Pseudo("first-child")
+}
+
+// LastChild adds :last-child
+func (sel *Selector) LastChild() *Selector {
+	return sel.Pseudo("last-child")
+}
+
+// NthChild adds :nth-child(expr), expr is an+b, odd or even
+func (sel *Selector) NthChild(expr string) *Selector {
+	return sel.nth("nth-child", expr)
+}
+
+// NthLastChild adds :nth-last-child(expr)
+func (sel *Selector) NthLastChild(expr string) *Selector {
+	return sel.nth("nth-last-child", expr)
+}
+
+// NthOfType adds :nth-of-type(expr)
+func (sel *Selector) NthOfType(expr string) *Selector {
+	return sel.nth("nth-of-type", expr)
+}
+
+// Not adds :not(other)
+func (sel *Selector) Not(other *Selector) *Selector {
+	if other.err != nil {
+		return sel.errorf("%v", other.err)
+	}
+	return sel.simple(":not(" + other.sb.String() + ")")
+}
+
+// PseudoElement adds a pseudo-element such as "before" or "placeholder", which must be last in the compound
+func (sel *Selector) PseudoElement(name string) *Selector {
+	name = strings.ToLower(name)
+	if !pseudoElements[name] {
+		return sel.errorf("unknown pseudo-element ::%s", name)
+	}
+	sel.simple("::" + name)
+	sel.pseudoElement = true
+	return sel
+}
+
+// Before adds ::before
+func (sel *Selector) Before() *Selector {
+	return sel.PseudoElement("before")
+}
+
+// After adds ::after
+func (sel *Selector) After() *Selector {
+	return sel.PseudoElement("after")
+}
+
+// combine joins other to the selector
+func (sel *Selector) combine(combinator string, other *Selector) *Selector {
+	if other.err != nil {
+		return sel.errorf("%v", other.err)
+	}
+	if sel.sb.Len() == 0 || other.sb.Len() == 0 {
+		return sel.errorf("combinator %q needs a selector on both sides", strings.TrimSpace(combinator))
+	}
+	sel.sb.WriteString(combinator)
+	sel.sb.WriteString(other.sb.String())
+	sel.pseudoElement = other.pseudoElement
+	return sel
+}
+
+// Descendant matches other anywhere inside the selector, "sel other"
+func (sel *Selector) Descendant(other *Selector) *Selector {
+	return sel.combine(" ", other)
+}
+
+// Child matches other directly inside the selector, "sel > other"
+func (sel *Selector) Child(other *Selector) *Selector {
+	return sel.combine(" > ", other)
+}
+
+// Adjacent matches other immediately after the selector, "sel + other"
+func (sel *Selector) Adjacent(other *Selector) *Selector {
+	return sel.combine(" + ", other)
+}
+
+// Sibling matches other anywhere after the selector with the same parent, "sel ~ other"
+func (sel *Selector) Sibling(other *Selector) *Selector {
+	return sel.combine(" ~ ", other)
+}
+
+// Err returns the first error found while building the selector, or any error found validating the complete selector
+func (sel *Selector) Err() error {
+	if sel.err != nil {
+		return sel.err
+	}
+	if _, err := parseSelectorList(sel.sb.String()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// String returns the selector as CSS
+func (sel *Selector) String() string {
+	return sel.sb.String()
+}
+
+// CSSEscape escapes s so that it can be used as a CSS identifier, a class name or id, as CSS.escape() does in a browser
+func CSSEscape(s string) string {
+	sb := strings.Builder{}
+	for i, r := range s {
+		switch {
+		case r == 0:
+			sb.WriteRune(utf8.RuneError)
+		case r < 0x20 || r == 0x7f || r == '<' || r == '>' || r == '&',
+			r >= '0' && r <= '9' && (i == 0 || (i == 1 && s[0] == '-')):
+			// markup characters are escaped as hex so that </style> can not appear in the output
+			sb.WriteString(`\` + strconv.FormatInt(int64(r), 16) + " ")
+		case r == '-' && i == 0 && len(s) == 1:
+			sb.WriteString(`\-`)
+		case r >= 0x80 || r == '-' || r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// CSSString returns s as a double quoted CSS string
+func CSSString(s string) string {
+	sb := strings.Builder{}
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == 0:
+			sb.WriteRune(utf8.RuneError)
+		case r == '"' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r < 0x20 || r == 0x7f || r == '<' || r == '>' || r == '&':
+			sb.WriteString(`\` + strconv.FormatInt(int64(r), 16) + " ")
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// isElementName returns true if s is a valid element name, letters, digits and -
+func isElementName(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && ((c >= '0' && c <= '9') || c == '-'))) {
+			return false
+		}
+	}
+	return len(s) > 0
+}
@@ -0,0 +1,105 @@
+package html
+
+import (
+	"testing"
+)
+
+func TestSelectorMatch(t *testing.T) {
+	ul := List(Unordered)
+	var items []*ListItemElement
+	for i := 0; i < 5; i++ {
+		items = append(items, ul.AddItem(Text("item")))
+	}
+	items[2].AddAttr("data-kind", "special-item")
+	div := Div(ul)
+	div.AddClassName("menu wide")
+	tree := newStyleTree(Div(div))
+
+	for _, tc := range []struct {
+		sel      string
+		expected []bool
+	}{
+		{"li", []bool{true, true, true, true, true}},
+		{"li:nth-child(2n+1)", []bool{true, false, true, false, true}},
+		{"li:nth-last-child(-n+2)", []bool{false, false, false, true, true}},
+		{"div.menu.wide > ul > li:not(:first-child):not(:last-child)", []bool{false, true, true, true, false}},
+		{".wide li[data-kind|=special]", []bool{false, false, true, false, false}},
+		{"li[data-kind] ~ li", []bool{false, false, false, true, true}},
+		{"li[data-kind] + li", []bool{false, false, false, true, false}},
+		{"p li", []bool{false, false, false, false, false}},
+	} {
+		sels, err := parseSelectorList(tc.sel)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []bool
+		tree.walk(func(n *styleNode) {
+			if n.tag == "li" {
+				got = append(got, sels[0].match(n))
+			}
+		})
+		for i := range got {
+			if got[i] != tc.expected[i] {
+				t.Errorf("%s: matched %v, expected %v", tc.sel, got, tc.expected)
+				break
+			}
+		}
+	}
+
+	for _, sel := range []string{"", "p >", "p..x", "[x=]", "li:nth-child(foo)", "a::before.x"} {
+		if _, err := parseSelectorList(sel); err == nil {
+			t.Errorf("%q: expected error", sel)
+		}
+	}
+}
+
+func TestSelectorBuilder(t *testing.T) {
+	for _, tc := range []struct {
+		sel      *Selector
+		expected string
+	}{
+		{Select("table").Class(NewClass("report")), "table.report"},
+		{SelectID("1st").Descendant(Select("a").Hover()), `#\31 st a:hover`},
+		{Select("UL").Child(Select("li").NthChild("2n + 1").Not(SelectClass(NewClass("skip")))), "ul > li:nth-child(2n+1):not(.skip)"},
+		{Select("input").AttrMatch("name", AttrPrefix, `a"</style>`), `input[name^="a\"\3c /style\3e "]`},
+		{Select("h1").Adjacent(Select("p")).Before(), "h1 + p::before"},
+		{Select("").ClassName("a.b"), `*.a\.b`},
+	} {
+		if err := tc.sel.Err(); err != nil {
+			t.Errorf("%s: %v", tc.expected, err)
+		}
+		if got := tc.sel.String(); got != tc.expected {
+			t.Errorf("selector %q, expected %q", got, tc.expected)
+		}
+	}
+
+	for _, sel := range []*Selector{
+		Select("1table"),
+		Select("a").Pseudo("hoover"),
+		Select("li").NthChild("3x"),
+		Select("p").After().ClassName("x"),
+		Select("p").Child(Select("bad name")),
+		Select("p").PseudoElement("nope"),
+	} {
+		if sel.Err() == nil {
+			t.Errorf("%q: expected error", sel.String())
+		}
+	}
+
+	style := NewStyle("s")
+	if err := style.AddElement("td"); err != nil {
+		t.Error(err)
+	}
+	if err := style.AddID("total"); err != nil {
+		t.Error(err)
+	}
+	if err := style.AddSelector(Select("a").Visited()); err != nil {
+		t.Error(err)
+	}
+	if err := style.AddElement("<td>"); err == nil {
+		t.Error("expected error for invalid element")
+	}
+	if got := len(style.associations); got != 3 {
+		t.Errorf("%d associations, expected 3", got)
+	}
+}
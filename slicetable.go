@@ -0,0 +1,288 @@
+package html
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SliceTable builds a TableElement from a slice of structs, see TableFromSlice
+type SliceTable struct {
+	slice  interface{}
+	hidden map[string]bool
+
+	// order is the field the rows are ordered by, descending if desc
+	order string
+	desc  bool
+
+	// link is the page the headers of orderable columns link to, with the order in the query value param
+	link  *URL
+	param string
+}
+
+// TableFromSlice builds a table of a slice of structs, or pointers to structs, with a column for each exported field.
+// Fields are found and titled the same way as FormFromStruct: the `html:"Title,format"` tag sets the title of
+// the column and the format of its values, "-" skips the field, and fields of nested structs are flattened.
+// Values are formatted like CellValue, slices are summarized, and columns of numbers are aligned right.
+// Fields with the orderable flag, `html:"Title,orderable"`, get header links which order the table, see OrderLinks.
+func TableFromSlice(slice interface{}) *SliceTable {
+	return &SliceTable{
+		slice:  slice,
+		hidden: make(map[string]bool),
+	}
+}
+
+// Hide leaves the fields out of the table, fields of nested structs are named "Outer.Inner"
+func (st *SliceTable) Hide(fields ...string) *SliceTable {
+	for _, f := range fields {
+		st.hidden[f] = true
+	}
+	return st
+}
+
+// OrderBy orders the rows by the field, descending if desc, without changing the slice
+func (st *SliceTable) OrderBy(field string, desc bool) *SliceTable {
+	st.order = field
+	st.desc = desc
+	return st
+}
+
+// OrderLinks makes the headers of orderable columns links to u, which order the table by the column.
+// The order is the query value param of u, the field name, with a "-" prefix for descending order.
+// If u has an order for an orderable field the rows are ordered by it.
+func (st *SliceTable) OrderLinks(u *URL, param string) *SliceTable {
+	st.link = u
+	st.param = param
+	return st
+}
+
+// Table returns the table, or an error if the slice is not a slice of structs
+func (st *SliceTable) Table() (*TableElement, error) {
+	rv := reflect.ValueOf(st.slice)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("table: %T is not a slice", st.slice)
+	}
+	et := rv.Type().Elem()
+	if et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	if et.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("table: %T is not a slice of structs", st.slice)
+	}
+
+	var fields []structField
+	for _, f := range structFields(et) {
+		if !st.hidden[f.name] {
+			fields = append(fields, f)
+		}
+	}
+
+	if st.link != nil {
+		if order := st.link.GetQuery(st.param); len(order) > 0 {
+			name := strings.TrimPrefix(order, "-")
+			for _, f := range fields {
+				if f.name == name && f.tag.flag("orderable") {
+					st.OrderBy(name, strings.HasPrefix(order, "-"))
+				}
+			}
+		}
+	}
+
+	table := Table()
+	header := table.Header()
+	for _, f := range fields {
+		cell := st.headerCell(header, f)
+		if f.name == st.order {
+			if st.desc {
+				cell.AddAttr("aria-sort", "descending")
+			} else {
+				cell.AddAttr("aria-sort", "ascending")
+			}
+		}
+	}
+
+	for _, i := range st.rowOrder(rv, fields) {
+		item := rv.Index(i)
+		row := table.Row()
+		for _, f := range fields {
+			cell := row.CellString(sliceCellText(item, f))
+			if isNumberType(f.typ) || (f.typ.Kind() == reflect.Ptr && isNumberType(f.typ.Elem())) {
+				cell.Right()
+			}
+		}
+	}
+	return table, nil
+}
+
+// headerCell adds the header of the field, a link which orders the table by it if the field is orderable
+func (st *SliceTable) headerCell(header *RowElement, f structField) *CellElement {
+	if st.link == nil || !f.tag.flag("orderable") {
+		return header.CellString(f.title)
+	}
+	order := f.name
+	if f.name == st.order && !st.desc {
+		order = "-" + f.name
+	}
+	u := st.link.Clone().AddQuery(st.param, order)
+	u.Element = Text(f.title)
+	return header.Cell(u)
+}
+
+// rowOrder returns the indexes of the items of the slice in the order of the table
+func (st *SliceTable) rowOrder(rv reflect.Value, fields []structField) []int {
+	order := make([]int, rv.Len())
+	for i := range order {
+		order[i] = i
+	}
+	for _, f := range fields {
+		if f.name != st.order {
+			continue
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			a, b := fieldValue(rv.Index(order[i]), f), fieldValue(rv.Index(order[j]), f)
+			if st.desc {
+				return compareValues(b, a) < 0
+			}
+			return compareValues(a, b) < 0
+		})
+	}
+	return order
+}
+
+// fieldValue returns the value of the field of an item, an invalid Value if the item or a struct on the way is nil
+func fieldValue(item reflect.Value, f structField) reflect.Value {
+	v := item
+	for _, i := range f.index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// sliceCellText formats the value of a field of an item for its cell
+func sliceCellText(item reflect.Value, f structField) string {
+	v := fieldValue(item, f)
+	if v.IsValid() && (v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 || v.Kind() == reflect.Map) {
+		return summarize(v, f.tag.format)
+	}
+	text, null := formatNullable(v, f.tag.format)
+	if null {
+		return NullDisplay
+	}
+	return text
+}
+
+// summarize formats a slice as its first few values, or a map or slice of structs as a count of its items
+func summarize(v reflect.Value, format string) string {
+	const shown = 3
+	n := v.Len()
+	if n == 0 {
+		return ""
+	}
+	et := v.Type().Elem()
+	if et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	if v.Kind() == reflect.Map || (et.Kind() == reflect.Struct && !isValueStruct(et)) {
+		if n == 1 {
+			return "1 item"
+		}
+		return fmt.Sprintf("%d items", n)
+	}
+	var items []string
+	for i := 0; i < n && i < shown; i++ {
+		items = append(items, formatValue(v.Index(i), format))
+	}
+	s := strings.Join(items, ", ")
+	if n > shown {
+		s += fmt.Sprintf(" and %d more", n-shown)
+	}
+	return s
+}
+
+// compareValues orders two field values: NULL first, then numbers, strings, times and bools by value, and others by text
+func compareValues(a reflect.Value, b reflect.Value) int {
+	a, b = sortValue(a), sortValue(b)
+	switch {
+	case !a.IsValid() && !b.IsValid():
+		return 0
+	case !a.IsValid():
+		return -1
+	case !b.IsValid():
+		return 1
+	}
+	if ta, ok := a.Interface().(time.Time); ok {
+		if tb, ok := b.Interface().(time.Time); ok {
+			switch {
+			case ta.Before(tb):
+				return -1
+			case ta.After(tb):
+				return 1
+			}
+			return 0
+		}
+	}
+	if a.Kind() == b.Kind() {
+		switch a.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return compareOrdered(a.Int() < b.Int(), a.Int() > b.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return compareOrdered(a.Uint() < b.Uint(), a.Uint() > b.Uint())
+		case reflect.Float32, reflect.Float64:
+			return compareOrdered(a.Float() < b.Float(), a.Float() > b.Float())
+		case reflect.Bool:
+			return compareOrdered(!a.Bool() && b.Bool(), a.Bool() && !b.Bool())
+		case reflect.String:
+			return strings.Compare(a.String(), b.String())
+		}
+	}
+	return strings.Compare(formatValue(a, ""), formatValue(b, ""))
+}
+
+func compareOrdered(less bool, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// sortValue returns the value to order v by: the value of a pointer or driver.Valuer, invalid for NULL
+func sortValue(v reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return reflect.Value{}
+		}
+		return v
+	}
+	if _, ok := v.Interface().(Enum); ok {
+		return v
+	}
+	if valuer, ok := v.Interface().(driver.Valuer); ok {
+		dv, err := valuer.Value()
+		if err != nil || dv == nil {
+			return reflect.Value{}
+		}
+		return reflect.ValueOf(dv)
+	}
+	return v
+}
@@ -0,0 +1,54 @@
+package html
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testOrder struct {
+	ID       int       `html:"No.,orderable"`
+	Customer string    `html:",orderable"`
+	Total    float64   `html:",%.2f,orderable"`
+	Placed   time.Time `html:",2006-01-02"`
+	Note     sql.NullString
+	Tags     []string
+	Lines    []testAddress
+	Ship     testAddress
+	internal int
+}
+
+func TestTableFromSlice(t *testing.T) {
+	defer func(old string) { NullDisplay = old }(NullDisplay)
+	NullDisplay = "-"
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	orders := []*testOrder{
+		{ID: 2, Customer: "Bob", Total: 5, Placed: day, Tags: []string{"a", "b", "c", "d"}, Ship: testAddress{City: "Oslo"}},
+		{ID: 1, Customer: "Ann", Total: 12.5, Note: sql.NullString{String: "gift", Valid: true}, Lines: []testAddress{{}, {}}},
+		nil,
+	}
+	table, err := TableFromSlice(orders).Hide("Ship.Zip").OrderLinks(NewLink("/orders?order=-Total"), "order").Table()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `No. </orders?order=ID>  Customer </orders?order=Customer>  Total </orders?order=Total>  Placed      Note  Tags                Lines    Ship City
+----------------------  ---------------------------------  ---------------------------  ----------  ----  ------------------  -------  ---------
+                     1  Ann                                                      12.50  -           gift                      2 items
+                     2  Bob                                                       5.00  2024-05-01  -     a, b, c and 1 more           Oslo
+                     -  -                                                            -  -           -     -                   -        -
+`
+	if got := PlainText(table); got != expected {
+		t.Errorf("got\n%s\nexpected\n%s", got, expected)
+	}
+	html := renderString(table.Write)
+	if !strings.Contains(html, `<th aria-sort="descending"><a href="/orders?order=Total">Total`) {
+		t.Errorf("order header in\n%s", html)
+	}
+
+	if _, err := TableFromSlice([]int{1}).Table(); err == nil {
+		t.Error("no error for a slice of ints")
+	}
+}
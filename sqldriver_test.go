@@ -0,0 +1,76 @@
+package html
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+)
+
+// testRows are the result of a query of the test driver, the query is the key in testQueries
+type testRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+var (
+	testQueries   = map[string]*testRows{}
+	testQueriesMu sync.Mutex
+	registerOnce  sync.Once
+)
+
+// testQuery opens a database whose query returns columns and rows, and runs it
+func testQuery(t *testing.T, columns []string, rows ...[]driver.Value) *sql.Rows {
+	registerOnce.Do(func() { sql.Register("htmltest", testDriver{}) })
+	testQueriesMu.Lock()
+	testQueries[t.Name()] = &testRows{columns: columns, rows: rows}
+	testQueriesMu.Unlock()
+
+	db, err := sql.Open("htmltest", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	r, err := db.Query(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+type testDriver struct{}
+
+func (testDriver) Open(name string) (driver.Conn, error) { return testConn{}, nil }
+
+type testConn struct{}
+
+func (testConn) Prepare(query string) (driver.Stmt, error) { return testStmt(query), nil }
+func (testConn) Close() error                              { return nil }
+func (testConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+type testStmt string
+
+func (s testStmt) Close() error  { return nil }
+func (s testStmt) NumInput() int { return 0 }
+func (s testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+func (s testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	testQueriesMu.Lock()
+	defer testQueriesMu.Unlock()
+	r := *testQueries[string(s)]
+	return &r, nil
+}
+
+func (r *testRows) Columns() []string { return r.columns }
+func (r *testRows) Close() error      { return nil }
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
@@ -0,0 +1,158 @@
+package html
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RowsTable builds a TableElement from the result of a query, see TableFromRows
+type RowsTable struct {
+	rows      *sql.Rows
+	titles    map[string]string
+	hidden    map[string]bool
+	renderers map[string]func(value interface{}) Element
+	limit     int
+}
+
+// TableFromRows builds a table of the result of a query, with a header of the column names and a row for each result.
+// Values are formatted by ValueText, and columns of numbers are aligned right. Set the options, then call Table to read the rows.
+func TableFromRows(rows *sql.Rows) *RowsTable {
+	return &RowsTable{
+		rows:      rows,
+		titles:    make(map[string]string),
+		hidden:    make(map[string]bool),
+		renderers: make(map[string]func(value interface{}) Element),
+	}
+}
+
+// Rename sets the header title of a column
+func (rt *RowsTable) Rename(column string, title string) *RowsTable {
+	rt.titles[column] = title
+	return rt
+}
+
+// Hide leaves the columns out of the table, they can still be used to order or join in the query
+func (rt *RowsTable) Hide(columns ...string) *RowsTable {
+	for _, c := range columns {
+		rt.hidden[c] = true
+	}
+	return rt
+}
+
+// Render sets the function which makes the content of each cell of a column, such as a URL for an id
+// render is called with the value of the column, nil for NULL
+func (rt *RowsTable) Render(column string, render func(value interface{}) Element) *RowsTable {
+	rt.renderers[column] = render
+	return rt
+}
+
+// Limit stops the table after n rows, followed by a notice that the result was truncated if there are more
+func (rt *RowsTable) Limit(n int) *RowsTable {
+	rt.limit = n
+	return rt
+}
+
+// Table reads the rows and returns the table. The rows are closed.
+func (rt *RowsTable) Table() (*TableElement, error) {
+	defer rt.rows.Close()
+	columns, err := rt.rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	numeric := make([]bool, len(columns))
+	if types, err := rt.rows.ColumnTypes(); err == nil {
+		for i, ct := range types {
+			numeric[i] = isNumericColumn(ct)
+		}
+	}
+
+	table := Table()
+	header := table.Header()
+	visible := 0
+	for _, c := range columns {
+		if rt.hidden[c] {
+			continue
+		}
+		title, ok := rt.titles[c]
+		if !ok {
+			title = c
+		}
+		header.CellString(title)
+		visible++
+	}
+
+	// cells are the cells of each column, aligned right once all the rows are read if the column type is a number,
+	// or every value which is not NULL is a number
+	cells := make([][]*CellElement, len(columns))
+	numbers := make([]int, len(columns))
+	text := make([]bool, len(columns))
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for n := 0; rt.rows.Next(); n++ {
+		if rt.limit > 0 && n == rt.limit {
+			table.Row().CellString(fmt.Sprintf("Showing the first %d rows", rt.limit)).Colspan(visible)
+			break
+		}
+		if err := rt.rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := table.Row()
+		for i, c := range columns {
+			if rt.hidden[c] {
+				continue
+			}
+			if render, ok := rt.renderers[c]; ok {
+				cells[i] = append(cells[i], row.Cell(render(values[i])))
+			} else {
+				cells[i] = append(cells[i], row.CellValue(values[i]))
+			}
+			if values[i] != nil {
+				numbers[i]++
+				if !isNumberValue(values[i]) {
+					text[i] = true
+				}
+			}
+		}
+	}
+	for i := range columns {
+		if numeric[i] || (numbers[i] > 0 && !text[i]) {
+			for _, cell := range cells[i] {
+				cell.Right()
+			}
+		}
+	}
+	return table, rt.rows.Err()
+}
+
+// numericTypes are the database type names of numbers, without sizes or digits such as INT4 or FLOAT8
+var numericTypes = map[string]bool{
+	"INT": true, "INTEGER": true, "BIGINT": true, "SMALLINT": true, "TINYINT": true, "MEDIUMINT": true,
+	"SERIAL": true, "BIGSERIAL": true, "SMALLSERIAL": true,
+	"DEC": true, "DECIMAL": true, "NUMERIC": true, "NUMBER": true,
+	"FLOAT": true, "REAL": true, "DOUBLE": true, "MONEY": true, "SMALLMONEY": true,
+}
+
+// isNumericColumn reports if the database type of a column is a number
+func isNumericColumn(ct *sql.ColumnType) bool {
+	return isNumericType(ct.DatabaseTypeName())
+}
+
+// isNumericType reports if a database type name, such as "DECIMAL(10,2)", "INT8" or "UNSIGNED BIGINT", is a number
+func isNumericType(name string) bool {
+	name = strings.ToUpper(name)
+	name = strings.TrimSpace(strings.Replace(name, "UNSIGNED", "", 1))
+	if i := strings.IndexAny(name, "( "); i >= 0 {
+		name = name[:i]
+	}
+	return numericTypes[strings.TrimRight(name, "0123456789")]
+}
+
+// isNumberValue reports if v is a number
+func isNumberValue(v interface{}) bool {
+	return v != nil && isNumberType(reflect.TypeOf(v))
+}
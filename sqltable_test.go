@@ -0,0 +1,76 @@
+package html
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTableFromRows(t *testing.T) {
+	defer func(old string) { NullDisplay = old }(NullDisplay)
+	NullDisplay = "-"
+
+	rows := testQuery(t, []string{"id", "name", "secret", "score"},
+		[]driver.Value{int64(1), []byte("Ann"), "x", 9.5},
+		[]driver.Value{int64(2), "Bob", "y", nil},
+		[]driver.Value{int64(3), "Cy", "z", 1.0},
+	)
+	table, err := TableFromRows(rows).
+		Rename("name", "Name").
+		Hide("secret").
+		Render("id", func(v interface{}) Element {
+			return NewLink(fmt.Sprintf("/users/%v", v)).SetName(fmt.Sprint(v))
+		}).
+		Limit(2).
+		Table()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `id                        Name  score
+------------------------  ----  -----
+            1 </users/1>  Ann     9.5
+            2 </users/2>  Bob       -
+Showing the first 2 rows
+`
+	if got := PlainText(table); got != expected {
+		t.Errorf("got\n%s\nexpected\n%s", got, expected)
+	}
+	html := renderString(table.Write)
+	for _, s := range []string{`<th>Name`, `<a href="/users/2">2</a>`, `colspan="3"`} {
+		if !strings.Contains(html, s) {
+			t.Errorf("missing %s in\n%s", s, html)
+		}
+	}
+	if strings.Contains(html, "secret") || strings.Contains(html, "Cy") {
+		t.Errorf("hidden column or row past the limit in\n%s", html)
+	}
+}
+
+func TestTableFromRowsMixedColumn(t *testing.T) {
+	rows := testQuery(t, []string{"code", "n"},
+		[]driver.Value{int64(1), int64(1)},
+		[]driver.Value{"A1", nil},
+	)
+	table, err := TableFromRows(rows).Table()
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := renderString(table.Write)
+	if n := strings.Count(html, "text-align:right"); n != 2 {
+		t.Errorf("%d right aligned cells, expected the 2 of the number column in\n%s", n, html)
+	}
+}
+
+func TestNumericColumnTypes(t *testing.T) {
+	for name, numeric := range map[string]bool{
+		"INT": true, "integer": true, "INT8": true, "BIGINT": true, "UNSIGNED INT": true, "INT UNSIGNED": true,
+		"DECIMAL(10,2)": true, "NUMERIC": true, "FLOAT8": true, "DOUBLE PRECISION": true, "MONEY": true,
+		"POINT": false, "MULTIPOINT": false, "INTERVAL": false, "VARCHAR": false, "TEXT": false, "": false,
+	} {
+		if got := isNumericType(name); got != numeric {
+			t.Errorf("%q numeric %v, expected %v", name, got, numeric)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package html
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// HTML input value layouts for times
+const (
+	dateLayout     = "2006-01-02"
+	datetimeLayout = "2006-01-02T15:04"
+	timeLayout     = "15:04"
+)
+
+var (
+	nullBoolType = reflect.TypeOf(sql.NullBool{})
+	nullTimeType = reflect.TypeOf(sql.NullTime{})
+)
+
+// FormFromStruct builds a form for the exported fields of a struct, or a pointer to one, with the current
+// values of the fields as defaults. Each field is a Field with a label and an input chosen from its type:
+// text for strings, number for ints and floats, a checkbox for bools, date for time.Time and a FormSelect for Enums,
+// sql.Null types are shown as their value, or empty when NULL. Fields of nested structs are named "Outer.Inner".
+// Values, labels and options are escaped, with AddTextAttr for attributes.
+//
+// The `html:"Title[,format][,key=value|flag]..."` tag sets the label, "-" skips the field.
+// Flags are required, readonly, hidden, textarea and password, options are
+// size, rows, cols, placeholder, min, max, minlength, maxlength, default, help, the help text of the field,
+// and options, a | separated list of values for a select. The format of a time.Time is a layout: a date, a time of day, or both.
+// min and max are checked by the form's validators, options which do not parse are an error.
+func FormFromStruct(action *URL, v interface{}) (*FormElement, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("form: %T is not a struct", v)
+	}
+
+	f := Form(action)
+	for _, field := range structFields(rv.Type()) {
+		input, err := structInput(f, field, field.value(rv))
+		if err != nil {
+			return nil, fmt.Errorf("form: field %s: %v", field.name, err)
+		}
+		if input == nil {
+			continue
+		}
+		if field.tag.flag("hidden") {
+			f.Add(input)
+			continue
+		}
+		input.AddAttr("id", f.FormName()+"-"+strings.ReplaceAll(field.name, ".", "-"))
+		fe := Field(field.title, input)
+		if help, ok := field.tag.options["help"]; ok {
+			fe.Help(help)
+		}
+		f.Add(fe)
+	}
+	return f, nil
+}
+
+// structInput returns the input for a field, with its validators added to the form, or nil if the type is not supported
+func structInput(f *FormElement, field structField, value reflect.Value) (Element, error) {
+	tag := field.tag
+	name := field.name
+	typ := field.typ
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	text := formatValue(value, "")
+	if def, ok := tag.options["default"]; ok && value.IsZero() {
+		text = def
+	}
+
+	if tag.flag("hidden") {
+		h := Hidden(name, "")
+		h.AddTextAttr("value", text)
+		return h, nil
+	}
+
+	var validators []*Validator
+	if tag.flag("required") {
+		validators = append(validators, Required())
+	}
+
+	var input Element
+	switch {
+	case typ.Implements(enumType) || len(tag.options["options"]) > 0:
+		sel := FormSelect(name)
+		if typ.Implements(enumType) {
+			current := reflect.Indirect(value)
+			if field.typ.Kind() == reflect.Ptr {
+				// a nil pointer is no value, rather than the first one
+				sel.textOption("", "").Selected(!current.IsValid())
+			}
+			for _, ev := range reflect.Zero(typ).Interface().(Enum).EnumValues() {
+				evv := reflect.ValueOf(ev)
+				sel.textOption(fmt.Sprint(ev), rawValue(evv)).Selected(current.IsValid() && rawValue(evv) == rawValue(current))
+			}
+		} else {
+			for _, opt := range strings.Split(tag.options["options"], "|") {
+				sel.textOption(opt, opt).Selected(opt == text)
+			}
+		}
+		input = sel
+
+	case typ.Kind() == reflect.Bool:
+		cb := Checkbox(name, "").SetChecked(text == "true")
+		cb.AddTextAttr("value", "true")
+		input = cb
+
+	case typ == nullBoolType:
+		sel := FormSelect(name)
+		sel.textOption("", "").Selected(text == "")
+		sel.textOption("Yes", "true").Selected(text == "true")
+		sel.textOption("No", "false").Selected(text == "false")
+		input = sel
+
+	case typ == timeType || typ == nullTimeType:
+		t := timeValue(value)
+		kind, layout := "date", dateLayout
+		if hasClock := strings.Contains(tag.format, "15") || strings.Contains(tag.format, "3"); hasClock {
+			kind, layout = "datetime-local", datetimeLayout
+			if !strings.Contains(tag.format, "2006") && !strings.Contains(tag.format, "06") {
+				kind, layout = "time", timeLayout
+			}
+		}
+		i := TextInput(name, 20)
+		i.AddAttr("type", kind)
+		if !t.IsZero() {
+			i.AddTextAttr("value", t.Format(layout))
+		}
+		input = i
+
+	case isNumberType(typ):
+		i := TextInput(name, 10)
+		i.AddAttr("type", "number")
+		if isFloatType(typ) {
+			i.AddAttr("step", "any")
+		}
+		i.AddTextAttr("value", text)
+		min, hasMin, err := tag.number("min")
+		if err != nil {
+			return nil, err
+		}
+		max, hasMax, err := tag.number("max")
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case hasMin && hasMax:
+			validators = append(validators, Range(min, max))
+		case hasMin:
+			validators = append(validators, Min(min))
+		case hasMax:
+			validators = append(validators, Max(max))
+		}
+		input = i
+
+	case typ.Kind() == reflect.String || typ.Implements(valuerType):
+		if tag.flag("textarea") {
+			rows, err := tag.count("rows", 4)
+			if err != nil {
+				return nil, err
+			}
+			cols, err := tag.count("cols", 40)
+			if err != nil {
+				return nil, err
+			}
+			input = TextArea(name, rows, cols).SetDefault(html.EscapeString(text))
+			break
+		}
+		i := TextInput(name, 20)
+		if tag.flag("password") {
+			i.AddAttr("type", "password")
+		} else {
+			i.AddTextAttr("value", text)
+		}
+		input = i
+
+	default:
+		return nil, nil
+	}
+
+	for _, key := range []string{"size", "placeholder"} {
+		if v, ok := tag.options[key]; ok {
+			input.(interface{ AddTextAttr(string, string) }).AddTextAttr(key, v)
+		}
+	}
+	if tag.flag("readonly") {
+		input.AddAttr("readonly", "true")
+	}
+	minLength, err := tag.count("minlength", -1)
+	if err != nil {
+		return nil, err
+	}
+	if minLength >= 0 {
+		validators = append(validators, MinLength(minLength))
+	}
+	maxLength, err := tag.count("maxlength", -1)
+	if err != nil {
+		return nil, err
+	}
+	if maxLength >= 0 {
+		validators = append(validators, MaxLength(maxLength))
+	}
+	if len(validators) > 0 {
+		f.AddValidator(name, validators...)
+	}
+	return input, nil
+}
+
+// timeValue returns the time of a time.Time or sql.NullTime, the zero time if it is NULL
+func timeValue(v reflect.Value) time.Time {
+	v = reflect.Indirect(v)
+	if !v.IsValid() {
+		return time.Time{}
+	}
+	switch t := v.Interface().(type) {
+	case time.Time:
+		return t
+	case sql.NullTime:
+		if t.Valid {
+			return t.Time
+		}
+	}
+	return time.Time{}
+}
+
+// isNumberType reports if t is a number, or a sql.Null number
+func isNumberType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	switch t {
+	case reflect.TypeOf(sql.NullInt64{}), reflect.TypeOf(sql.NullInt32{}), reflect.TypeOf(sql.NullFloat64{}):
+		return true
+	}
+	return false
+}
+
+func isFloatType(t reflect.Type) bool {
+	return t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64 || t == reflect.TypeOf(sql.NullFloat64{})
+}
@@ -0,0 +1,270 @@
+package html
+
+import (
+	"database/sql"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testPriority int
+
+func (p testPriority) String() string {
+	return [...]string{"Low", "Normal", "High"}[p]
+}
+
+func (testPriority) EnumValues() []interface{} {
+	return []interface{}{testPriority(0), testPriority(1), testPriority(2)}
+}
+
+type testAddress struct {
+	City string `html:",required"`
+	Zip  string `html:"Postcode,maxlength=10"`
+}
+
+type testTicket struct {
+	ID       int          `html:",hidden"`
+	Title    string       `html:"Summary,required,minlength=3"`
+	Notes    string       `html:",textarea,rows=3"`
+	Estimate float64      `html:",min=0,max=100"`
+	Urgent   bool         `html:"Urgent?"`
+	Due      time.Time    `html:",2006-01-02"`
+	Closed   sql.NullTime `html:",2006-01-02 15:04"`
+	Owner    sql.NullString
+	Priority testPriority
+	Status   string `html:",options=open|closed"`
+	Address  testAddress
+	Secret   string `html:"-"`
+	internal string
+}
+
+func TestFormFromStruct(t *testing.T) {
+	ticket := &testTicket{
+		ID:       7,
+		Title:    `Fix "quotes"`,
+		Estimate: 2.5,
+		Urgent:   true,
+		Due:      time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		Owner:    sql.NullString{String: "ann", Valid: true},
+		Priority: 2,
+		Status:   "closed",
+		Address:  testAddress{City: "Oslo"},
+		internal: "x",
+	}
+	form, err := FormFromStruct(NewLink("/ticket"), ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	form.SetName("ticket")
+
+	html := renderString(form.Write)
+	for _, s := range []string{
+		`<input name="ID" type="hidden" value="7">`,
+		`>Summary</label>`,
+		`minlength="3" name="Title" required size="20" type="text" value="Fix &#34;quotes&#34;">`,
+		`<textarea cols="40" id="`,
+		`max="100" min="0" name="Estimate" size="10" step="any" type="number" value="2.5"`,
+		`checked id="`,
+		`name="Due" size="20" type="date" value="2024-03-01"`,
+		`name="Closed" size="20" type="datetime-local">`,
+		`value="ann"`,
+		`<option selected value="2">High</option>`,
+		`<option selected value="closed">closed</option>`,
+		`>Address City</label>`,
+		`>Address Postcode</label>`,
+		`name="Address.City" required`,
+	} {
+		if !strings.Contains(html, s) {
+			t.Errorf("missing %s in:\n%s", s, html)
+		}
+	}
+	for _, s := range []string{"Secret", "internal"} {
+		if strings.Contains(html, s) {
+			t.Errorf("field %s should be skipped", s)
+		}
+	}
+
+	if _, err := FormFromStruct(NewLink("/x"), 3); err == nil {
+		t.Error("expected error")
+	}
+}
+
+type testFlags struct {
+	Name      string
+	Active    bool
+	Archived  bool
+	Confirmed sql.NullBool
+	Checked   sql.NullBool
+	Unknown   sql.NullBool
+	Priority  testPriority
+}
+
+// TestFormFromStructRoundTrip renders a form, submits it the way a browser would and decodes the values
+func TestFormFromStructRoundTrip(t *testing.T) {
+	for _, in := range []testFlags{
+		{Name: "a", Active: true, Confirmed: sql.NullBool{Bool: true, Valid: true}, Checked: sql.NullBool{Valid: true}, Priority: 2},
+		{Name: "b", Archived: true, Priority: 1},
+	} {
+		form, err := FormFromStruct(NewLink("/flags"), in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		html := renderString(form.Write)
+		var out testFlags
+		if err := DecodeForm(submitForm(html), &out); err != nil {
+			t.Fatal(err)
+		}
+		if out != in {
+			t.Errorf("decoded %+v\nexpected %+v\nfrom\n%s", out, in, html)
+		}
+	}
+}
+
+type testEvent struct {
+	Start time.Time  `html:"Start,2006-01-02 15:04"`
+	Day   *time.Time `html:"Day"`
+}
+
+func TestFormFromStructTimeLocation(t *testing.T) {
+	oslo := time.FixedZone("CET", 3600)
+	day := time.Date(2024, 3, 5, 0, 0, 0, 0, oslo)
+	in := testEvent{Start: time.Date(2024, 3, 5, 23, 30, 0, 0, oslo), Day: &day}
+	form, err := FormFromStruct(NewLink("/event"), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := renderString(form.Write)
+	// decoding into the current record parses the times in its location
+	out := in
+	if err := DecodeForm(submitForm(html), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !out.Start.Equal(in.Start) || out.Day == nil || !out.Day.Equal(day) {
+		t.Errorf("decoded %v %v, expected %v %v\nfrom\n%s", out.Start, out.Day, in.Start, day, html)
+	}
+	if out.Start.Location() != oslo {
+		t.Errorf("location %v", out.Start.Location())
+	}
+}
+
+var (
+	formInputRE  = regexp.MustCompile(`<input([^>]*)>`)
+	formSelectRE = regexp.MustCompile(`(?s)<select([^>]*)>(.*?)</select>`)
+	formOptionRE = regexp.MustCompile(`<option([^>]*)>`)
+	formAreaRE   = regexp.MustCompile(`(?s)<textarea([^>]*)>(.*?)</textarea>`)
+	formAttrRE   = regexp.MustCompile(`([a-z-]+)(="([^"]*)")?`)
+)
+
+// submitForm returns the values a browser submits for the rendered form
+func submitForm(form string) url.Values {
+	attrs := func(s string) map[string]string {
+		m := make(map[string]string)
+		for _, a := range formAttrRE.FindAllStringSubmatch(s, -1) {
+			m[a[1]] = html.UnescapeString(a[3])
+		}
+		return m
+	}
+	values := make(url.Values)
+	for _, m := range formInputRE.FindAllStringSubmatch(form, -1) {
+		a := attrs(m[1])
+		name, value, hasValue := a["name"], a["value"], strings.Contains(m[1], `value=`)
+		switch a["type"] {
+		case "submit", "reset", "image", "button", "file":
+			continue
+		case "checkbox", "radio":
+			if _, checked := a["checked"]; !checked {
+				continue
+			}
+			if !hasValue {
+				value = "on"
+			}
+		}
+		values.Add(name, value)
+	}
+	for _, m := range formSelectRE.FindAllStringSubmatch(form, -1) {
+		name := attrs(m[1])["name"]
+		var first, selected []string
+		for _, o := range formOptionRE.FindAllStringSubmatch(m[2], -1) {
+			a := attrs(o[1])
+			if _, disabled := a["disabled"]; disabled {
+				continue
+			}
+			if first == nil {
+				first = []string{a["value"]}
+			}
+			if _, ok := a["selected"]; ok {
+				selected = append(selected, a["value"])
+			}
+		}
+		if selected == nil {
+			selected = first
+		}
+		values[name] = append(values[name], selected...)
+	}
+	for _, m := range formAreaRE.FindAllStringSubmatch(form, -1) {
+		values.Add(attrs(m[1])["name"], html.UnescapeString(strings.TrimSuffix(m[2], "\n")))
+	}
+	return values
+}
+
+func TestFormFromStructNilEnum(t *testing.T) {
+	type task struct {
+		Priority *testPriority
+	}
+	high := testPriority(2)
+	for _, in := range []task{{}, {Priority: &high}} {
+		form, err := FormFromStruct(NewLink("/task"), in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		html := renderString(form.Write)
+		if !strings.Contains(html, `<option value="">`) && !strings.Contains(html, `<option selected value="">`) {
+			t.Errorf("no empty option in\n%s", html)
+		}
+		var out task
+		if err := DecodeForm(submitForm(html), &out); err != nil {
+			t.Fatal(err)
+		}
+		if (out.Priority == nil) != (in.Priority == nil) || (out.Priority != nil && *out.Priority != *in.Priority) {
+			t.Errorf("decoded %v, expected %v from\n%s", out.Priority, in.Priority, html)
+		}
+	}
+}
+
+func TestFormFromStructBounds(t *testing.T) {
+	var bounded struct {
+		Age   int     `html:",min=18"`
+		Score float64 `html:",max=10"`
+	}
+	form, err := FormFromStruct(NewLink("/bounds"), &bounded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := form.Validate(url.Values{"Age": {"17"}, "Score": {"11"}})
+	if errs["Age"] != "Must be a number of at least 18" || errs["Score"] != "Must be a number of at most 10" {
+		t.Errorf("errors %v", errs)
+	}
+	if errs := form.Validate(url.Values{"Age": {"18"}, "Score": {"10"}}); len(errs) > 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	html := renderString(form.Write)
+	if !strings.Contains(html, `min="18"`) || !strings.Contains(html, `max="10"`) {
+		t.Errorf("missing constraints in\n%s", html)
+	}
+
+	var bad struct {
+		Age int `html:",min=eighteen"`
+	}
+	if _, err := FormFromStruct(NewLink("/bounds"), &bad); err == nil || !strings.Contains(err.Error(), "Age") {
+		t.Errorf("expected error for the min of Age, got %v", err)
+	}
+	var badLength struct {
+		Name string `html:",maxlength=ten"`
+	}
+	if _, err := FormFromStruct(NewLink("/bounds"), &badLength); err == nil {
+		t.Error("expected error for maxlength")
+	}
+}
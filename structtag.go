@@ -0,0 +1,182 @@
+package html
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Enum is implemented by types with a fixed set of values, such as a set of int constants.
+// Generated forms show an Enum field as a FormSelect of its values, each displayed with fmt.Sprint,
+// so a String method gives the display name.
+type Enum interface {
+	EnumValues() []interface{}
+}
+
+// tagFlags are the options of an html struct tag which do not take a value
+var tagFlags = map[string]bool{
+	"required":  true,
+	"readonly":  true,
+	"hidden":    true,
+	"textarea":  true,
+	"password":  true,
+	"orderable": true,
+}
+
+// fieldTag is a parsed struct tag, `html:"Title[,format][,key=value|flag]..."`
+// Title "-" skips the field, an empty title is made from the field name.
+// The second item is the format of the value, unless it is an option.
+type fieldTag struct {
+	title   string
+	format  string
+	skip    bool
+	options map[string]string
+}
+
+func parseFieldTag(tag string) fieldTag {
+	ft := fieldTag{options: make(map[string]string)}
+	if tag == "-" {
+		ft.skip = true
+		return ft
+	}
+	parts := strings.Split(tag, ",")
+	ft.title = strings.TrimSpace(parts[0])
+	for i, p := range parts[1:] {
+		p = strings.TrimSpace(p)
+		switch eq := strings.IndexByte(p, '='); {
+		case len(p) == 0:
+		case eq > 0:
+			ft.options[strings.TrimSpace(p[:eq])] = strings.TrimSpace(p[eq+1:])
+		case tagFlags[p] || i > 0:
+			ft.options[p] = "true"
+		default:
+			ft.format = p
+		}
+	}
+	return ft
+}
+
+// flag reports if the flag is set
+func (ft fieldTag) flag(name string) bool {
+	return ft.options[name] == "true"
+}
+
+// number returns the value of a number option and if it is set, an option which is not a number is an error
+func (ft fieldTag) number(name string) (float64, bool, error) {
+	s, ok := ft.options[name]
+	if !ok {
+		return 0, false, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("option %s=%q is not a number", name, s)
+	}
+	return f, true, nil
+}
+
+// count returns the value of a whole number option, or def if it is not set, an option which is not a whole number is an error
+func (ft fieldTag) count(name string, def int) (int, error) {
+	s, ok := ft.options[name]
+	if !ok {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("option %s=%q is not a whole number", name, s)
+	}
+	return n, nil
+}
+
+// structField is an exported field of a struct, fields of nested structs are flattened
+type structField struct {
+	// name is the dotted path of the field, "Address.City"
+	name  string
+	title string
+	index []int
+	typ   reflect.Type
+	tag   fieldTag
+}
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	enumType   = reflect.TypeOf((*Enum)(nil)).Elem()
+)
+
+// structFields returns the exported fields of the struct type t, in order, with nested structs flattened
+// Embedded structs add their fields without a prefix
+func structFields(t reflect.Type) []structField {
+	return appendStructFields(nil, t, nil, "", "")
+}
+
+func appendStructFields(fields []structField, t reflect.Type, index []int, name string, title string) []structField {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if len(f.PkgPath) > 0 && !f.Anonymous {
+			continue
+		}
+		tag := parseFieldTag(f.Tag.Get("html"))
+		if tag.skip {
+			continue
+		}
+		idx := append(append([]int(nil), index...), i)
+
+		fname, ftitle := f.Name, tag.title
+		if len(ftitle) == 0 {
+			ftitle = titleFromName(f.Name)
+		}
+		if f.Type.Kind() == reflect.Struct && !isValueStruct(f.Type) {
+			if f.Anonymous {
+				fields = appendStructFields(fields, f.Type, idx, name, title)
+			} else {
+				fields = appendStructFields(fields, f.Type, idx, joinName(name, fname, "."), joinName(title, ftitle, " "))
+			}
+			continue
+		}
+		if len(f.PkgPath) > 0 {
+			continue
+		}
+		fields = append(fields, structField{
+			name:  joinName(name, fname, "."),
+			title: joinName(title, ftitle, " "),
+			index: idx,
+			typ:   f.Type,
+			tag:   tag,
+		})
+	}
+	return fields
+}
+
+func joinName(prefix string, name string, sep string) string {
+	if len(prefix) == 0 {
+		return name
+	}
+	return prefix + sep + name
+}
+
+// isValueStruct reports if a struct type is a single value, such as time.Time or sql.NullString, rather than a set of fields
+func isValueStruct(t reflect.Type) bool {
+	return t == timeType || t.Implements(valuerType) || reflect.PtrTo(t).Implements(valuerType) || t.Implements(enumType)
+}
+
+// titleFromName splits a field name into words, "FirstName" is "First Name" and "HTTPPort" is "HTTP Port"
+func titleFromName(name string) string {
+	r := []rune(name)
+	sb := strings.Builder{}
+	for i, c := range r {
+		if i > 0 && unicode.IsUpper(c) && (unicode.IsLower(r[i-1]) || (i+1 < len(r) && unicode.IsLower(r[i+1]) && unicode.IsUpper(r[i-1]))) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
+
+// value returns the value of the field in the struct v
+func (f structField) value(v reflect.Value) reflect.Value {
+	return v.FieldByIndex(f.index)
+}
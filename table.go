@@ -1,8 +1,6 @@
 package html
 
 import (
-	"database/sql"
-	"fmt"
 	"strconv"
 )
 
@@ -117,19 +115,9 @@ func (row *RowElement) CellStrings(ss ...string) {
 	}
 }
 
-// CellString adds an string element to the table
+// CellInt adds a number or sql.Null number to the table, see CellValue
 func (row *RowElement) CellInt(value interface{}) *CellElement {
-	var text string
-	switch value.(type) {
-	case sql.NullInt64:
-		sv := value.(sql.NullInt64)
-		if sv.Valid {
-			text = strconv.FormatInt(sv.Int64, 10)
-		}
-	default:
-		text = fmt.Sprintf("%d", value)
-	}
-	return row.Cell(Text(text))
+	return row.CellValue(value)
 }
 
 // CellStrings adds an string element to the table
@@ -171,6 +159,28 @@ func (cell *CellElement) Fg(color string) *CellElement {
 	return cell
 }
 
+// BgColor sets the background color of the cell, and unless FgColor or Fg has set one,
+// a text color, black or white, which is readable on it
+func (cell *CellElement) BgColor(color Color) *CellElement {
+	hasFg := false
+	for _, d := range parseDeclarations(cell.GetAttr("style")) {
+		if d.Property == "color" {
+			hasFg = true
+		}
+	}
+	cell.Style("background-color", color.String())
+	if !hasFg {
+		cell.Style("color", color.Readable().String())
+	}
+	return cell
+}
+
+// FgColor sets the text color of the cell
+func (cell *CellElement) FgColor(color Color) *CellElement {
+	cell.Style("color", color.String())
+	return cell
+}
+
 func (cell *CellElement) Center() *CellElement {
 	cell.Style("text-align", "center")
 	return cell
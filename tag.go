@@ -1,6 +1,7 @@
 package html
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 	"strings"
@@ -10,6 +11,19 @@ import (
 // it contains an io write which the HTML document is rendered into
 type TagWriter struct {
 	w http.ResponseWriter
+
+	// nonce is added to script and style tags, for the Content-Security-Policy
+	nonce string
+
+	// gathered is set while a Document is written, which writes the JavaScript of its elements in one script
+	gathered bool
+
+	// fieldErrors are the errors of the form being written, see FormElement.SetErrors
+	fieldErrors *fieldErrors
+
+	// csrfField and csrfToken are written as a hidden field in POST forms, see CSRF
+	csrfField string
+	csrfToken string
 }
 
 // HtmlTag defines the open/close structure for the tag
@@ -35,6 +49,7 @@ var (
 	TagDiv      = HtmlTag{Open: "<div>",      Close: "</div>"}
 	TagDl       = HtmlTag{Open: "<dl>",       Close: "</dl>"}
 	TagDt       = HtmlTag{Open: "<dt>",       Close: "</dt>"}
+	TagFieldset = HtmlTag{Open: "<fieldset>", Close: "</fieldset>"}
 	TagForm     = HtmlTag{Open: "<form>",     Close: "</form>"}
 	TagH1       = HtmlTag{Open: "<h1>",       Close: "</h1>"}
 	TagH2       = HtmlTag{Open: "<h2>",       Close: "</h2>"}
@@ -49,16 +64,20 @@ var (
 	TagImg      = HtmlTag{Open: "<img>",      Close: ""}
 	TagInput    = HtmlTag{Open: "<input>",    Close: ""}
 	TagLabel    = HtmlTag{Open: "<label>",    Close: "</label>"}
+	TagLegend   = HtmlTag{Open: "<legend>",   Close: "</legend>"}
 	TagLi       = HtmlTag{Open: "<li>",       Close: "</li>"}
+	TagLink     = HtmlTag{Open: "<link>",     Close: ""}
 	TagMap      = HtmlTag{Open: "<map>",      Close: "</map>"}
 	TagMeta     = HtmlTag{Open: "<meta>",     Close: ""}
 	TagOl       = HtmlTag{Open: "<ol>",       Close: "</ol>"}
+	TagOptGroup = HtmlTag{Open: "<optgroup>", Close: "</optgroup>"}
 	TagOption   = HtmlTag{Open: "<option>",   Close: "</option>"}
 	TagP        = HtmlTag{Open: "<p>",        Close: "</p>"}
 	TagPre      = HtmlTag{Open: "<pre>",      Close: "</pre>"}
 	TagScript   = HtmlTag{Open: "<script>",   Close: "</script>"}
 	TagSelect   = HtmlTag{Open: "<select>",   Close: "</select>"}
 	TagSource   = HtmlTag{Open: "<source>",   Close: ""}
+	TagSpan     = HtmlTag{Open: "<span>",     Close: "</span>"}
 	TagStyle    = HtmlTag{Open: "<style>",    Close: "</style>"}
 	TagTable    = HtmlTag{Open: "<table>",    Close: "</table>"}
 	TagTd       = HtmlTag{Open: "<td>",       Close: "</td>"}
@@ -83,7 +102,10 @@ func NewTagWriter(w http.ResponseWriter) *TagWriter {
 func (tw *TagWriter) WriteTag(tag HtmlTag, e Element) {
 	open := tag.Open
 	if e != nil {
-		attrs := e.GetAttrs()
+		attrs := tw.fieldAttrs(tag, e)
+		if len(tw.nonce) > 0 && (tag == TagScript || tag == TagStyle) {
+			attrs += ` nonce="` + tw.nonce + `"`
+		}
 		if len(attrs) > 0 {
 			open = strings.Replace(open, ">", attrs+">", 1)
 		}
@@ -92,6 +114,35 @@ func (tw *TagWriter) WriteTag(tag HtmlTag, e Element) {
 	e.WriteContent(tw)
 	tw.WriteString(tag.Close)
 	tw.Nl()
+	if e != nil {
+		tw.writeFieldError(tag, e)
+	}
+}
+
+// renderString runs fn with a TagWriter that collects the output in a string
+func renderString(fn func(tw *TagWriter)) string {
+	var b bytes.Buffer
+	fn(NewTagWriter(&bufferWriter{w: &b}))
+	return b.String()
+}
+
+// escapeCloseTag escapes any </tag in the content of a raw text element, such as style or script, so it can not end the element early
+// The / is written as \/ which has the same meaning inside CSS and JavaScript strings
+func escapeCloseTag(s string, tag string) string {
+	sb := strings.Builder{}
+	last := 0
+	for i := 0; i+2+len(tag) <= len(s); i++ {
+		if s[i] == '<' && s[i+1] == '/' && strings.EqualFold(s[i+2:i+2+len(tag)], tag) {
+			sb.WriteString(s[last : i+1])
+			sb.WriteString(`\/`)
+			last = i + 2
+		}
+	}
+	if last == 0 {
+		return s
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
 }
 
 // WriteString writes a string to the io.Writer
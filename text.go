@@ -159,3 +159,21 @@ func I(elements ...Element) *ItalicElement {
 func (e *ItalicElement) Write(tw *TagWriter) {
 	tw.WriteTag(TagI, e)
 }
+
+type SpanElement struct {
+	Container
+}
+
+// Span creates a new Span Container (span), an inline container for styling
+func Span(elements ...Element) *SpanElement {
+	e := &SpanElement{}
+	if len(elements) > 0 {
+		e.Add(elements...)
+	}
+	return e
+}
+
+// Write writes the Span contents
+func (e *SpanElement) Write(tw *TagWriter) {
+	tw.WriteTag(TagSpan, e)
+}
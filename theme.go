@@ -0,0 +1,177 @@
+package html
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Class names on the html element which force a color scheme, see Document.SetColorScheme
+const (
+	SchemeLight = "light"
+	SchemeDark  = "dark"
+)
+
+// Theme is a set of design tokens, colors, spacing, fonts, written as CSS custom properties on :root.
+// Tokens may have a different value in dark mode, which is used when the browser prefers a dark color scheme,
+// unless the html element has the class SchemeLight, or always when it has the class SchemeDark.
+// Styles refer to tokens by name with Var or StyleVar, so one set of Styles serves both schemes.
+type Theme struct {
+	// tokens are the token names in the order they were added
+	tokens []string
+	light  map[string]string
+	dark   map[string]string
+	err    error
+}
+
+// NewTheme creates an empty theme
+func NewTheme() *Theme {
+	return &Theme{
+		light: make(map[string]string),
+		dark:  make(map[string]string),
+	}
+}
+
+// Set sets the value of a token, used in both light and dark schemes unless SetDark overrides it
+// name may be given with or without the leading --
+func (t *Theme) Set(name string, value string) *Theme {
+	if name, ok := t.token(name, value); ok {
+		t.light[name] = value
+	}
+	return t
+}
+
+// SetDark sets the value of a token in the dark scheme
+func (t *Theme) SetDark(name string, value string) *Theme {
+	if name, ok := t.token(name, value); ok {
+		t.dark[name] = value
+	}
+	return t
+}
+
+// SetColor sets a color token, with its light and dark values
+func (t *Theme) SetColor(name string, light Color, dark Color) *Theme {
+	return t.Set(name, light.String()).SetDark(name, dark.String())
+}
+
+// token checks the name and value of a token and adds it to the list of tokens
+func (t *Theme) token(name string, value string) (string, bool) {
+	name = strings.TrimPrefix(name, "--")
+	if len(name) == 0 || strings.IndexFunc(name, func(r rune) bool { return r < 0x80 && !isNameChar(byte(r)) }) >= 0 {
+		if t.err == nil {
+			t.err = fmt.Errorf("theme: invalid token name %q", name)
+		}
+		return "", false
+	}
+	if len(strings.TrimSpace(value)) == 0 || strings.ContainsAny(value, "{};<") {
+		if t.err == nil {
+			t.err = fmt.Errorf("theme: invalid value %q for token %q", value, name)
+		}
+		return "", false
+	}
+	if _, ok := t.light[name]; !ok {
+		if _, ok := t.dark[name]; !ok {
+			t.tokens = append(t.tokens, name)
+		}
+	}
+	return name, true
+}
+
+// Get returns the light value of a token, or the dark value if it only has one
+func (t *Theme) Get(name string) string {
+	name = strings.TrimPrefix(name, "--")
+	if v, ok := t.light[name]; ok {
+		return v
+	}
+	return t.dark[name]
+}
+
+// Err returns the first invalid token or value set on the theme
+func (t *Theme) Err() error {
+	return t.err
+}
+
+// String returns the theme as CSS
+func (t *Theme) String() string {
+	sb := &strings.Builder{}
+	hasDark := len(t.dark) > 0
+
+	writeTokens := func(selector string, scheme string, values map[string]string) {
+		sb.WriteString(selector + " {\n")
+		if len(scheme) > 0 {
+			sb.WriteString("    color-scheme: " + scheme + ";\n")
+		}
+		for _, name := range t.tokens {
+			if v, ok := values[name]; ok {
+				sb.WriteString("    --" + name + ": " + v + ";\n")
+			}
+		}
+		sb.WriteString("}\n")
+	}
+
+	if hasDark {
+		writeTokens(":root", "light dark", t.light)
+		sb.WriteString("@media " + MediaDark + " {\n")
+		writeTokens(":root:not(."+SchemeLight+")", "", t.dark)
+		sb.WriteString("}\n")
+		writeTokens(":root."+SchemeLight, SchemeLight, nil)
+		writeTokens(":root."+SchemeDark, SchemeDark, t.dark)
+	} else {
+		writeTokens(":root", "", t.light)
+	}
+	return sb.String()
+}
+
+// CSS returns the theme as CSSData, which can be added to a Document or a CSSBundle
+func (t *Theme) CSS() *CSSData {
+	css := CSS(t.String())
+	if t.err != nil {
+		css.err = t.err
+	}
+	return css
+}
+
+// Var returns a reference to a theme token for use in a style value, Var("accent") is "var(--accent)"
+// Optional fallback is used by the browser when the token is not defined
+func Var(name string, fallback ...string) string {
+	name = "--" + strings.TrimPrefix(name, "--")
+	if len(fallback) > 0 {
+		return "var(" + name + ", " + strings.Join(fallback, ", ") + ")"
+	}
+	return "var(" + name + ")"
+}
+
+// StyleVar defines a style whose value is a theme token, StyleVar("color", "text")
+func StyleVar(key string, token string) StyleDef {
+	return StyleDef{
+		Key:   key,
+		Value: Var(token),
+	}
+}
+
+// AddTheme adds the tokens of a theme to the document, ahead of any Styles which use them,
+// returning any errors found parsing them
+func (doc *Document) AddTheme(theme *Theme) error {
+	css := theme.CSS()
+	doc.AddCSS(css)
+	return css.Err()
+}
+
+// SetColorScheme forces the document into SchemeLight or SchemeDark, regardless of the browser preference
+// An empty scheme follows the browser preference. Other classes of the html tag are kept.
+func (doc *Document) SetColorScheme(scheme string) {
+	var classes []string
+	for _, c := range strings.Fields(doc.GetAttr("class")) {
+		if c != doc.scheme && c != scheme {
+			classes = append(classes, c)
+		}
+	}
+	if len(scheme) > 0 {
+		classes = append(classes, scheme)
+	}
+	doc.scheme = scheme
+	if len(classes) == 0 {
+		delete(doc.attrs, "class")
+		return
+	}
+	doc.AddClassName(strings.Join(classes, " "))
+}
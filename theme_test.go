@@ -0,0 +1,54 @@
+package html
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTheme(t *testing.T) {
+	theme := NewTheme().
+		SetColor("text", Black, White).
+		SetColor("--bg", White, MustColor("#121212")).
+		Set("space", "4px").
+		Set("font", `"Open Sans", sans-serif`)
+
+	doc := NewDocument()
+	if err := doc.AddTheme(theme); err != nil {
+		t.Fatal(err)
+	}
+	body := NewStyle("body", StyleVar("color", "text"), StyleVar("background", "bg"), StyleDef{Key: "padding", Value: Var("space", "2px")})
+	body.AddElement("body")
+	doc.AddStyle(body)
+	doc.SetColorScheme(SchemeDark)
+
+	html := renderString(doc.Write)
+	for _, s := range []string{
+		`<html class="dark">`,
+		":root {\n    color-scheme: light dark;\n    --text: #000000;\n    --bg: #ffffff;\n    --space: 4px;\n",
+		"@media (prefers-color-scheme: dark) {\n:root:not(.light) {\n    --text: #ffffff;\n    --bg: #121212;\n}\n}",
+		":root.dark {\n    color-scheme: dark;\n    --text: #ffffff;",
+		"color: var(--text);",
+		"padding: var(--space, 2px);",
+	} {
+		if !strings.Contains(html, s) {
+			t.Errorf("missing %q in:\n%s", s, html)
+		}
+	}
+
+	doc.AddClassName("js dark")
+	doc.SetColorScheme(SchemeLight)
+	if class := doc.GetAttr("class"); class != "js light" {
+		t.Errorf("class %q after light", class)
+	}
+	doc.SetColorScheme("")
+	if class := doc.GetAttr("class"); class != "js" {
+		t.Errorf("class %q after no scheme", class)
+	}
+
+	if err := NewTheme().Set("bad name", "1px").Err(); err == nil {
+		t.Error("expected invalid name error")
+	}
+	if err := doc.AddTheme(NewTheme().Set("x", "1px; } body { color: red")); err == nil {
+		t.Error("expected invalid value error")
+	}
+}
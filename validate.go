@@ -0,0 +1,292 @@
+package html
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// emailPattern is the HTML5 definition of a valid email address, https://html.spec.whatwg.org/#valid-e-mail-address
+const emailPattern = "[a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*"
+
+// Validator is a rule for the value of a form field. It is written as HTML5 constraint attributes on the field
+// and as JavaScript run when the form is submitted, and FormElement.Validate checks the same rule on the server.
+// Apart from Required, validators accept an empty value.
+type Validator struct {
+	// attrs are the HTML5 constraint attributes
+	attrs map[string]string
+
+	// check reports if a non-empty value is valid
+	check func(value string) bool
+
+	// js is a JavaScript expression which is true if v, the non-empty value, is valid
+	js string
+
+	msg      string
+	required bool
+}
+
+// Required requires the field to have a value
+func Required() *Validator {
+	return &Validator{
+		attrs:    map[string]string{"required": "true"},
+		msg:      "This field is required",
+		required: true,
+	}
+}
+
+// MinLength requires at least n characters. Lengths are counted in UTF-16 code units on both sides,
+// as browsers count them for minlength and maxlength, so a character outside the BMP, such as an emoji, counts as 2.
+func MinLength(n int) *Validator {
+	return &Validator{
+		attrs: map[string]string{"minlength": strconv.Itoa(n)},
+		check: func(v string) bool { return utf16Len(v) >= n },
+		js:    "v.length >= " + strconv.Itoa(n),
+		msg:   fmt.Sprintf("Must be at least %d characters", n),
+	}
+}
+
+// MaxLength allows at most n characters, counted like MinLength
+func MaxLength(n int) *Validator {
+	return &Validator{
+		attrs: map[string]string{"maxlength": strconv.Itoa(n)},
+		check: func(v string) bool { return utf16Len(v) <= n },
+		js:    "v.length <= " + strconv.Itoa(n),
+		msg:   fmt.Sprintf("Must be at most %d characters", n),
+	}
+}
+
+// utf16Len returns the length of s in UTF-16 code units, the length of a JavaScript string
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+// Range requires a number from min to max
+func Range(min float64, max float64) *Validator {
+	smin := strconv.FormatFloat(min, 'f', -1, 64)
+	smax := strconv.FormatFloat(max, 'f', -1, 64)
+	return &Validator{
+		attrs: map[string]string{"min": smin, "max": smax},
+		check: func(v string) bool {
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			return err == nil && f >= min && f <= max
+		},
+		js:  "!isNaN(Number(v)) && Number(v) >= " + smin + " && Number(v) <= " + smax,
+		msg: fmt.Sprintf("Must be a number from %s to %s", smin, smax),
+	}
+}
+
+// Min requires a number of at least min
+func Min(min float64) *Validator {
+	smin := formatFloat(min)
+	return &Validator{
+		attrs: map[string]string{"min": smin},
+		check: func(v string) bool {
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			return err == nil && f >= min
+		},
+		js:  "!isNaN(Number(v)) && Number(v) >= " + smin,
+		msg: fmt.Sprintf("Must be a number of at least %s", smin),
+	}
+}
+
+// Max requires a number of at most max
+func Max(max float64) *Validator {
+	smax := formatFloat(max)
+	return &Validator{
+		attrs: map[string]string{"max": smax},
+		check: func(v string) bool {
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			return err == nil && f <= max
+		},
+		js:  "!isNaN(Number(v)) && Number(v) <= " + smax,
+		msg: fmt.Sprintf("Must be a number of at most %s", smax),
+	}
+}
+
+// Pattern requires the whole value to match the regular expression re
+// re is checked by Go on the server and by JavaScript in the browser, so it must be valid in both: use only syntax
+// common to RE2 and JavaScript, without lookarounds or backreferences. Pattern panics if Go can not compile re,
+// like regexp.MustCompile.
+func Pattern(re string) *Validator {
+	v := newPattern(re, "Must match the required format")
+	v.attrs = map[string]string{"pattern": re}
+	return v
+}
+
+// EmailAddress requires a valid email address, and makes a text input an email input
+func EmailAddress() *Validator {
+	v := newPattern(emailPattern, "Must be a valid email address")
+	v.attrs = map[string]string{"type": "email"}
+	return v
+}
+
+func newPattern(re string, msg string) *Validator {
+	anchored := "^(?:" + re + ")$"
+	compiled, err := regexp.Compile(anchored)
+	if err != nil {
+		panic(fmt.Errorf("pattern %q: %v", re, err))
+	}
+	return &Validator{
+		check: compiled.MatchString,
+		js:    "new RegExp(" + JSString(anchored) + ").test(v)",
+		msg:   msg,
+	}
+}
+
+// OneOf requires the value to be one of values
+func OneOf(values ...string) *Validator {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = JSString(v)
+	}
+	return &Validator{
+		check: func(v string) bool { return containsString(values, v) },
+		js:    "[" + strings.Join(quoted, ", ") + "].indexOf(v) >= 0",
+		msg:   "Must be one of " + strings.Join(values, ", "),
+	}
+}
+
+// Message replaces the default error message of the validator
+func (v *Validator) Message(msg string) *Validator {
+	v.msg = msg
+	return v
+}
+
+// validate returns the error message for values, or "" if they are valid
+func (v *Validator) validate(values []string) string {
+	filled := false
+	for _, s := range values {
+		if len(s) == 0 {
+			continue
+		}
+		filled = true
+		if v.check != nil && !v.check(s) {
+			return v.msg
+		}
+	}
+	if v.required && !filled {
+		return v.msg
+	}
+	return ""
+}
+
+// javaScript returns the validation of the field for the form validation function,
+// value is the expression of the value of the field and focus the element to focus when it is not valid
+func (v *Validator) javaScript(value string, focus string) string {
+	cond := "v.length < 1"
+	if !v.required {
+		cond = "v.length > 0 && !(" + v.js + ")"
+	}
+	script := " if ((function(v) { return " + cond + "; })(" + value + ")) {\n"
+	script += "  alert(" + JSString(v.msg) + ");\n"
+	script += "  " + focus + ".focus();\n"
+	script += "  return false;\n"
+	script += " }\n"
+	return script
+}
+
+// fieldValidators are the validators of a field
+type fieldValidators struct {
+	field      string
+	validators []*Validator
+}
+
+// AddValidator adds validators to the field called name
+// The rules are checked in the browser before the form is submitted, and by Validate on the server
+func (f *FormElement) AddValidator(name string, validators ...*Validator) *FormElement {
+	for i := range f.validators {
+		if f.validators[i].field == name {
+			f.validators[i].validators = append(f.validators[i].validators, validators...)
+			return f
+		}
+	}
+	f.validators = append(f.validators, fieldValidators{field: name, validators: validators})
+	return f
+}
+
+// Validate checks submitted values against the validators of the form and returns the first error of each
+// field that fails, keyed by field name, or nil if all fields are valid
+func (f *FormElement) Validate(values url.Values) map[string]string {
+	var errs map[string]string
+	for _, fv := range f.validators {
+		for _, v := range fv.validators {
+			if msg := v.validate(values[fv.field]); len(msg) > 0 {
+				if errs == nil {
+					errs = make(map[string]string)
+				}
+				errs[fv.field] = msg
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// validatorJavaScript returns the checks of the validators for the form validation function
+func (f *FormElement) validatorJavaScript() string {
+	sb := strings.Builder{}
+	groups := f.choiceGroups()
+	for _, fv := range f.validators {
+		form := "document.forms[" + JSString(f.FormName()) + "]"
+		value := form + ".elements[" + JSString(fv.field) + "].value"
+		focus := form + ".elements[" + JSString(fv.field) + "]"
+		if g, ok := groups[fv.field]; ok {
+			// the checked values of a group, joined with commas
+			value = "Array.prototype.map.call(" + form + ".querySelectorAll(" + JSString(`input[name="`+fv.field+`"]:checked`) +
+				"), function(e) { return e.value; }).join(\",\")"
+			focus = "document.getElementById(" + JSString(g.inputs[0].GetAttr("id")) + ")"
+		}
+		for _, v := range fv.validators {
+			sb.WriteString(v.javaScript(value, focus))
+		}
+	}
+	return sb.String()
+}
+
+// choiceGroups returns the radio and checkbox groups of the form with inputs, by name
+func (f *FormElement) choiceGroups() map[string]*ChoiceGroupElement {
+	groups := make(map[string]*ChoiceGroupElement)
+	Walk(f, func(e Element) bool {
+		if g, ok := e.(*ChoiceGroupElement); ok && len(g.inputs) > 0 {
+			groups[g.name] = g
+		}
+		return true
+	})
+	return groups
+}
+
+// applyConstraints adds the HTML5 constraint attributes of the validators to the fields of the form
+func (f *FormElement) applyConstraints() {
+	if len(f.validators) == 0 {
+		return
+	}
+	groups := f.choiceGroups()
+	Walk(f, func(e Element) bool {
+		name := e.GetAttr("name")
+		if e == Element(f) || len(name) == 0 {
+			return true
+		}
+		if _, ok := groups[name]; ok && e.GetAttr("type") == "checkbox" {
+			// required on each checkbox would require all of them, the group is checked by the script
+			return true
+		}
+		for _, fv := range f.validators {
+			if fv.field != name {
+				continue
+			}
+			for _, v := range fv.validators {
+				for k, value := range v.attrs {
+					if k == "type" && e.GetAttr("type") != "text" {
+						continue
+					}
+					e.AddAttr(k, value)
+				}
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,95 @@
+package html
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestFormValidate(t *testing.T) {
+	form := Form(NewLink("/signup")).SetName("signup").
+		AddValidator("user", Required(), MinLength(3), MaxLength(8), Pattern("[a-z]+")).
+		AddValidator("email", Required(), EmailAddress()).
+		AddValidator("age", Range(18, 130).Message("Adults only")).
+		AddValidator("plan", OneOf("free", "pro"))
+	form.Add(TextInput("user", 10), TextInput("email", 20), TextInput("age", 3), TextInput("plan", 5))
+
+	for _, tc := range []struct {
+		values   url.Values
+		expected map[string]string
+	}{
+		{url.Values{"user": {"bob"}, "email": {"bob@example.com"}, "age": {"30"}, "plan": {"pro"}}, nil},
+		{url.Values{"user": {"bob"}, "email": {"bob@example.com"}}, nil},
+		{url.Values{}, map[string]string{"user": "This field is required", "email": "This field is required"}},
+		{url.Values{"user": {"Bo"}, "email": {"bob"}, "age": {"12"}, "plan": {"gold"}}, map[string]string{
+			"user":  "Must be at least 3 characters",
+			"email": "Must be a valid email address",
+			"age":   "Adults only",
+			"plan":  "Must be one of free, pro",
+		}},
+		{url.Values{"user": {"Bobby"}, "email": {"a@b"}, "age": {"old"}}, map[string]string{
+			"user": "Must match the required format",
+			"age":  "Adults only",
+		}},
+	} {
+		errs := form.Validate(tc.values)
+		if len(errs) != len(tc.expected) {
+			t.Errorf("%v: errors %v, expected %v", tc.values, errs, tc.expected)
+			continue
+		}
+		for k, v := range tc.expected {
+			if errs[k] != v {
+				t.Errorf("%v: %s error %q, expected %q", tc.values, k, errs[k], v)
+			}
+		}
+	}
+
+	html := renderString(form.Write)
+	for _, s := range []string{
+		`maxlength="8" minlength="3" name="user" pattern="[a-z]+" required`,
+		`name="email" required size="20" type="email"`,
+		`max="130" min="18" name="age"`,
+		`alert("Adults only");`,
+		`["free", "pro"].indexOf(v) >= 0`,
+		`addEventListener("submit"`,
+	} {
+		if !strings.Contains(html, s) {
+			t.Errorf("missing %s in:\n%s", s, html)
+		}
+	}
+}
+
+func TestLengthUnits(t *testing.T) {
+	// each emoji is one rune but two UTF-16 code units, as the browser counts it
+	for _, tc := range []struct {
+		v     *Validator
+		value string
+		ok    bool
+	}{
+		{MaxLength(2), "ab", true},
+		{MaxLength(2), "\U0001F600", true},
+		{MaxLength(2), "a\U0001F600", false},
+		{MinLength(2), "\U0001F600", true},
+		{MinLength(2), "é", false},
+	} {
+		if got := tc.v.validate([]string{tc.value}) == ""; got != tc.ok {
+			t.Errorf("%q: valid %v, expected %v", tc.value, got, tc.ok)
+		}
+	}
+}
+
+func TestPatternInvalid(t *testing.T) {
+	for _, re := range []string{"[", `(?<=a)b`} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("no panic for %q", re)
+				}
+			}()
+			Pattern(re)
+		}()
+	}
+	if msg := Pattern("[a-z]+").validate([]string{"abc"}); msg != "" {
+		t.Errorf("valid pattern: %s", msg)
+	}
+}
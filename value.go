@@ -0,0 +1,147 @@
+package html
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NullDisplay is the text of a table cell with a NULL value, such as an invalid sql.NullString, a nil pointer or a zero time
+// Form inputs with NULL values are always empty
+var NullDisplay = ""
+
+// ValueText formats a value for a table cell or form input, and reports if it is NULL.
+// The sql.Null types and other driver.Valuer types are formatted as their value, []byte as a string,
+// a time.Time as RFC 3339 and numbers without exponents. Types with a String method use it.
+func ValueText(value interface{}) (text string, null bool) {
+	if value == nil {
+		return "", true
+	}
+	return formatNullable(reflect.ValueOf(value), "")
+}
+
+// CellValue adds a cell with any value, formatted by ValueText, NULL is shown as NullDisplay
+func (row *RowElement) CellValue(value interface{}) *CellElement {
+	text, null := ValueText(value)
+	if null {
+		text = NullDisplay
+	}
+	return row.CellString(text)
+}
+
+// SetDefaultValue sets the default of the input to any value, formatted by ValueText, NULL clears it
+// Numbers and strings with a String method, such as an Enum, are set to their underlying value, which decodes
+func (i *InputElement) SetDefaultValue(value interface{}) *InputElement {
+	text, null := inputValueText(value)
+	if null {
+		delete(i.attrs, "value")
+		return i
+	}
+	i.AddTextAttr("value", text)
+	return i
+}
+
+// OptionValue adds an option with any value, formatted like SetDefaultValue, NULL is ""
+func (e *FormSelectElement) OptionValue(display string, value interface{}) *OptionElement {
+	text, _ := inputValueText(value)
+	opt := e.Option(display, text)
+	opt.AddTextAttr("value", text)
+	return opt
+}
+
+// inputValueText formats a value for a form, like ValueText but ignoring the String method of numbers and strings
+func inputValueText(value interface{}) (string, bool) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", true
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.String:
+		return rawValue(v), false
+	}
+	return formatNullable(v, "")
+}
+
+// formatValue formats a field value as text, with format as a fmt verb, or a time layout for times
+// NULL values, nil pointers and zero times are empty
+func formatValue(v reflect.Value, format string) string {
+	text, _ := formatNullable(v, format)
+	return text
+}
+
+// formatNullable formats a value like formatValue, and reports if it is NULL
+func formatNullable(v reflect.Value, format string) (string, bool) {
+	if !v.IsValid() {
+		return "", true
+	}
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", true
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return "", true
+		}
+		if len(format) == 0 {
+			format = time.RFC3339
+		}
+		return t.Format(format), false
+	}
+	if b, ok := v.Interface().([]byte); ok {
+		if b == nil {
+			return "", true
+		}
+		return string(b), false
+	}
+	if valuer, ok := v.Interface().(driver.Valuer); ok {
+		dv, err := valuer.Value()
+		if err != nil || dv == nil {
+			return "", true
+		}
+		if _, ok := v.Interface().(fmt.Stringer); !ok {
+			return formatNullable(reflect.ValueOf(dv), format)
+		}
+	}
+	if len(format) > 0 && strings.Contains(format, "%") {
+		return fmt.Sprintf(format, v.Interface()), false
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), false
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, ok := v.Interface().(fmt.Stringer); !ok {
+			return strconv.FormatInt(v.Int(), 10), false
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, ok := v.Interface().(fmt.Stringer); !ok {
+			return strconv.FormatUint(v.Uint(), 10), false
+		}
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), false
+	}
+	return fmt.Sprint(v.Interface()), false
+}
+
+// rawValue formats the underlying value of v, ignoring any String method, such as the value of an Enum option
+func rawValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.String:
+		return v.String()
+	}
+	return formatValue(v, "")
+}
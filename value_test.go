@@ -0,0 +1,89 @@
+package html
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValueText(t *testing.T) {
+	day := time.Date(2024, 3, 9, 8, 30, 0, 0, time.UTC)
+	var nilInt *int
+	for _, tc := range []struct {
+		value interface{}
+		text  string
+		null  bool
+	}{
+		{nil, "", true},
+		{42, "42", false},
+		{uint8(7), "7", false},
+		{2.50, "2.5", false},
+		{"text", "text", false},
+		{[]byte("bytes"), "bytes", false},
+		{day, "2024-03-09T08:30:00Z", false},
+		{time.Time{}, "", true},
+		{nilInt, "", true},
+		{sql.NullString{String: "s", Valid: true}, "s", false},
+		{sql.NullString{}, "", true},
+		{sql.NullInt64{Int64: -3, Valid: true}, "-3", false},
+		{sql.NullInt32{Int32: 5, Valid: true}, "5", false},
+		{sql.NullInt32{}, "", true},
+		{sql.NullFloat64{Float64: 0.125, Valid: true}, "0.125", false},
+		{sql.NullBool{Bool: true, Valid: true}, "true", false},
+		{sql.NullTime{Time: day, Valid: true}, "2024-03-09T08:30:00Z", false},
+		{sql.NullTime{}, "", true},
+		{testPriority(2), "High", false},
+	} {
+		text, null := ValueText(tc.value)
+		if text != tc.text || null != tc.null {
+			t.Errorf("%#v: got %q %v, expected %q %v", tc.value, text, null, tc.text, tc.null)
+		}
+	}
+}
+
+func TestValueEntryPoints(t *testing.T) {
+	defer func(old string) { NullDisplay = old }(NullDisplay)
+	NullDisplay = "NULL"
+
+	tbl := Table()
+	row := tbl.Row()
+	row.CellValue(sql.NullString{})
+	row.CellInt(sql.NullInt32{Int32: 12, Valid: true})
+	row.CellInt(7)
+	html := renderString(tbl.Write)
+	if !strings.Contains(html, "<td>NULL\n</td>") || !strings.Contains(html, "<td>12\n</td>") || !strings.Contains(html, "<td>7\n</td>") {
+		t.Errorf("cells\n%s", html)
+	}
+
+	in := TextInput("n", 5).SetDefault("old").SetDefaultValue(sql.NullFloat64{})
+	if got := renderString(in.Write); strings.Contains(got, "value") {
+		t.Errorf("NULL default %s", got)
+	}
+	if got := TextInput("n", 5).SetDefaultInt(sql.NullInt64{Int64: 9, Valid: true}).GetAttr("value"); got != "9" {
+		t.Errorf("default %q", got)
+	}
+	if got := FormSelect("s").OptionInt("nine", uint(9)).GetAttr("value"); got != "9" {
+		t.Errorf("option %q", got)
+	}
+}
+
+func TestValueStringerInputs(t *testing.T) {
+	high := testPriority(2)
+	if got := FormSelect("p").OptionInt("High", high).GetAttr("value"); got != "2" {
+		t.Errorf("OptionInt value %q", got)
+	}
+	if got := FormSelect("p").OptionValue("High", &high).GetAttr("value"); got != "2" {
+		t.Errorf("OptionValue value %q", got)
+	}
+	if got := TextInput("p", 5).SetDefaultInt(high).GetAttr("value"); got != "2" {
+		t.Errorf("SetDefaultInt value %q", got)
+	}
+	if got := TextInput("p", 5).SetDefaultValue(sql.NullInt32{Int32: 4, Valid: true}).GetAttr("value"); got != "4" {
+		t.Errorf("SetDefaultValue value %q", got)
+	}
+	row := Table().Row()
+	if got := renderString(row.CellInt(high).Write); !strings.Contains(got, "High") {
+		t.Errorf("cell %q", got)
+	}
+}
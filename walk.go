@@ -0,0 +1,167 @@
+package html
+
+import "strings"
+
+// elementContainer is implemented by every element that embeds a Container
+type elementContainer interface {
+	containerElements() []Element
+}
+
+// containerElements returns the elements held by the container
+func (c *Container) containerElements() []Element {
+	return c.elements
+}
+
+// Walk calls fn for e and then for each of its descendants, depth first, in the order they are rendered.
+// If fn returns false the children of that element are skipped
+func Walk(e Element, fn func(e Element) bool) {
+	if e == nil || !fn(e) {
+		return
+	}
+	for _, child := range children(e) {
+		Walk(child, fn)
+	}
+}
+
+// children returns the direct child elements of e
+func children(e Element) []Element {
+	var kids []Element
+	switch t := e.(type) {
+	case *Document:
+		kids = append(kids, t.head, t.body)
+	case *TableElement:
+		for _, row := range t.rows {
+			kids = append(kids, row)
+		}
+	case *RowElement:
+		for _, cell := range t.cells {
+			kids = append(kids, cell)
+		}
+	case *CellElement:
+		if t.data != nil {
+			kids = append(kids, t.data)
+		}
+	case *ListElement:
+		for _, item := range t.items {
+			kids = append(kids, item)
+		}
+	case *ListItemElement:
+		if t.data != nil {
+			kids = append(kids, t.data)
+		}
+		if t.desc != nil {
+			kids = append(kids, t.desc)
+		}
+	case *HeadingElement:
+		if t.data != nil {
+			kids = append(kids, t.data)
+		}
+	case *URL:
+		if t.Element != nil {
+			kids = append(kids, t.Element)
+		}
+	case *MapElement:
+		for _, item := range t.items {
+			kids = append(kids, item)
+		}
+	case *FormSelectElement:
+		if t.placeholder != nil {
+			kids = append(kids, t.placeholder)
+		}
+		kids = append(kids, t.items...)
+	case *OptGroupElement:
+		for _, opt := range t.options {
+			kids = append(kids, opt)
+		}
+	case elementContainer:
+		kids = t.containerElements()
+	}
+	return kids
+}
+
+// tagName returns the name of the HTML tag an element is rendered as, or "" for elements without a tag such as Text
+func tagName(e Element) string {
+	var tag HtmlTag
+	switch t := e.(type) {
+	case *Document:
+		tag = TagHtml
+	case *HeadElement:
+		tag = TagHead
+	case *BodyElement:
+		tag = TagBody
+	case *DivElement, *ChoiceGroupElement:
+		tag = TagDiv
+	case *ParagraphElement:
+		tag = TagP
+	case *BoldElement:
+		tag = TagB
+	case *ItalicElement:
+		tag = TagI
+	case *SpanElement:
+		tag = TagSpan
+	case *PreElement:
+		tag = TagPre
+	case *HeadingElement:
+		tag = []HtmlTag{TagH1, TagH2, TagH3, TagH4, TagH5, TagH6}[t.level-1]
+	case *TableElement:
+		tag = TagTable
+	case *RowElement:
+		tag = TagTr
+	case *CellElement:
+		tag = t.tagType
+	case *ListElement:
+		tag = []HtmlTag{TagDl, TagOl, TagUl}[t.listType]
+	case *ListItemElement:
+		tag = TagLi
+		if t.listType == Description {
+			tag = TagDt
+		}
+	case *URL:
+		tag = TagA
+	case *ImageElement:
+		tag = TagImg
+	case *MapElement:
+		tag = TagMap
+	case *AreaElement:
+		tag = TagArea
+	case *FormElement:
+		tag = TagForm
+	case *InputElement, *CheckboxElement:
+		tag = TagInput
+	case *LabelElement:
+		tag = TagLabel
+	case *FieldsetElement:
+		tag = TagFieldset
+	case *LegendElement:
+		tag = TagLegend
+	case *FieldElement:
+		tag = t.tag()
+	case *TextAreaElement:
+		tag = TagTextArea
+	case *FormSelectElement:
+		tag = TagSelect
+	case *OptGroupElement:
+		tag = TagOptGroup
+	case *OptionElement:
+		tag = TagOption
+	case *ButtonElement:
+		tag = TagButton
+	case *AudioElement:
+		tag = TagAudio
+	case *SourceElement:
+		tag = TagSource
+	case *IFrameElement:
+		tag = TagIFrame
+	case *ScriptElement:
+		tag = TagScript
+	case *StyleElement, *CSSElement:
+		tag = TagStyle
+	case *MetaElement:
+		tag = TagMeta
+	case *Title:
+		tag = TagTitle
+	case *BreakElement:
+		tag = TagBr
+	}
+	return strings.Trim(tag.Open, "<>")
+}